@@ -1,23 +1,35 @@
 package migration
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"news_service/models"
+	"news_service/services/category_service"
+	"news_service/services/embedding_service"
+	"news_service/services/language_service"
+	"news_service/services/synonym_service"
 	"news_service/utils"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
 )
 
 type Service struct {
-	mySqlClient *utils.MySQLConn
-	esClient    *elasticsearch.Client
+	mySqlClient    *utils.MySQLConn
+	esClient       *elasticsearch.Client
+	synonymService *synonym_service.SynonymService
 }
 
-func NewMigrationService(mySqlClient *utils.MySQLConn, esClient *elasticsearch.Client) Service {
-	return Service{mySqlClient: mySqlClient, esClient: esClient}
+func NewMigrationService(mySqlClient *utils.MySQLConn, esClient *elasticsearch.Client,
+	synonymService *synonym_service.SynonymService) Service {
+	return Service{mySqlClient: mySqlClient, esClient: esClient, synonymService: synonymService}
 }
 
 func (m *Service) migrate(entity interface{}) (err error) {
@@ -39,9 +51,13 @@ func (m *Service) InitMigration() {
 func (m *Service) dbMigration() {
 	log.Printf("goroutine::DB table migration started...")
 	dbTables := map[string]interface{}{
-		"news":            &models.News{},
-		"users":           &models.User{},
-		"user_activities": &models.UserActivity{},
+		"news":             &models.News{},
+		"users":            &models.User{},
+		"user_activities":  &models.UserActivity{},
+		"categories":       &models.Category{},
+		"category_aliases": &models.CategoryAlias{},
+		"news_categories":  &models.NewsCategory{},
+		"sources":          &models.Source{},
 	}
 
 	for tableName, table := range dbTables {
@@ -50,31 +66,311 @@ func (m *Service) dbMigration() {
 			log.Fatalf("migration failed for table: %v", tableName)
 		}
 	}
+	if db := m.mySqlClient.DB; db != nil {
+		if err := category_service.SeedCategories(db, os.Getenv("CATEGORIES_FILE")); err != nil {
+			log.Printf("Failed to seed categories: %v", err)
+		}
+	}
 	log.Printf("goroutine::DB table migration concluded")
 }
 
 func (m *Service) indexCreationAndMapping() {
-	createIndexRes, err := m.esClient.Indices.Create(
-		"news",
-		m.esClient.Indices.Create.WithContext(context.Background()),
+	// The search analyzer refers to the synonyms sets, so they have to exist first.
+	ctx := &utils.Context{Ctx: context.Background()}
+	if err := m.synonymService.EnsureSynonyms(ctx); err != nil {
+		log.Fatalf("Failed to create synonyms set: %v", err)
+	}
+
+	index, upToDate, err := m.currentNewsIndex()
+	if err != nil {
+		log.Fatalf("Failed to get index settings: %v", err)
+	}
+	if index == "" {
+		if err = m.createNewsIndex(newsIndexName(), true); err != nil {
+			log.Fatalf("Failed to create index: %v", err)
+		}
+		return
+	}
+	if !upToDate {
+		if err = m.reindexNews(index); err != nil {
+			log.Printf("Failed to move news to the current analysis settings, still serving %v: %v", index, err)
+		}
+		return
+	}
+
+	putMappingRes, err := m.esClient.Indices.PutMapping(
+		[]string{utils.NEWS_INDEX},
+		strings.NewReader(newsMapping()),
+		m.esClient.Indices.PutMapping.WithContext(context.Background()),
 	)
 	if err != nil {
-		log.Fatalf("Failed to create index: %v", err)
+		log.Fatalf("Failed to put mapping: %v", err)
 	}
-	fmt.Println("Create Index Response: ", createIndexRes)
+	if putMappingRes.IsError() {
+		log.Printf("Failed to put mapping: %v", putMappingRes)
+	}
+}
 
-	mapping := `
+// newsMapping returns the field mappings of the news index.
+func newsMapping() string {
+	return fmt.Sprintf(`
 	{
 	    "properties": {
-	        "location": { "type": "geo_point" }
+	        "location": { "type": "geo_point" },
+	        "language": { "type": "keyword" },
+	        "category_ids": { "type": "long" },
+	        "source_id": { "type": "long" },
+	        "description": {
+	            "type": "text",
+	            "analyzer": "default",
+	            "search_analyzer": "%[3]s",
+	            "fields": {
+	                "keyword": { "type": "keyword", "ignore_above": 256 },%[2]s
+	            }
+	        },
+	        "embedding": {
+	            "type": "dense_vector",
+	            "dims": %[1]d,
+	            "index": true,
+	            "similarity": "cosine"
+	        },
+	        "title": {
+	            "type": "text",
+	            "analyzer": "default",
+	            "search_analyzer": "%[3]s",
+	            "fields": {
+	                "keyword": { "type": "keyword", "ignore_above": 256 },
+	                "suggest": { "type": "search_as_you_type" },%[2]s
+	            }
+	        },
+	        "source_name": {
+	            "type": "text",
+	            "fields": {
+	                "keyword": { "type": "keyword", "ignore_above": 256 },
+	                "suggest": { "type": "search_as_you_type" }
+	            }
+	        },
+	        "city": {
+	            "type": "text",
+	            "fields": {
+	                "keyword": { "type": "keyword", "ignore_above": 256 }
+	            }
+	        },
+	        "region": {
+	            "type": "text",
+	            "fields": {
+	                "keyword": { "type": "keyword", "ignore_above": 256 }
+	            }
+	        },
+	        "country": { "type": "keyword" },
+	        "category": {
+	            "type": "text",
+	            "fields": {
+	                "keyword": { "type": "keyword", "ignore_above": 256 },
+	                "suggest": { "type": "search_as_you_type" }
+	            }
+	        }
 	    }
-	}`
-	_, err = m.esClient.Indices.PutMapping(
-		[]string{"news"},
-		strings.NewReader(mapping),
-		m.esClient.Indices.PutMapping.WithContext(context.Background()),
+	}`, embedding_service.EMBEDDING_DIMS, languageSubfields(), synonym_service.SEARCH_ANALYZER)
+}
+
+// analysisSettings defines the search analyzer used for title and
+// description. Its synonym and stop word filters are updateable, so editing
+// synonyms or stop words only needs a search analyzer reload. Changing the
+// analyzer itself goes through reindexNews.
+func analysisSettings() string {
+	return fmt.Sprintf(`
+	{
+	    "settings": {
+	        "analysis": {
+	            "filter": {
+	                "news_synonyms": {
+	                    "type": "synonym_graph",
+	                    "synonyms_set": "%[1]v",
+	                    "updateable": true
+	                },
+	                "news_stopwords": {
+	                    "type": "synonym_graph",
+	                    "synonyms_set": "%[2]v",
+	                    "updateable": true
+	                },
+	                "news_stopword_token": {
+	                    "type": "stop",
+	                    "stopwords": ["%[3]v"]
+	                }
+	            },
+	            "analyzer": {
+	                "%[4]v": {
+	                    "type": "custom",
+	                    "tokenizer": "standard",
+	                    "filter": ["lowercase", "news_synonyms", "news_stopwords", "news_stopword_token"]
+	                }
+	            }
+	        }
+	    }
+	}`, synonym_service.SYNONYMS_SET, synonym_service.STOPWORDS_SET,
+		synonym_service.STOPWORD_TOKEN, synonym_service.SEARCH_ANALYZER)
+}
+
+// newsIndexName returns a new versioned name for the index behind the news
+// alias, e.g. news-20250131120000.
+func newsIndexName() string {
+	return fmt.Sprintf("%v-%v", utils.NEWS_INDEX, time.Now().Format("20060102150405"))
+}
+
+// currentNewsIndex returns the index the news name resolves to, or an empty
+// string when there is none, and whether it has the current search analyzer.
+func (m *Service) currentNewsIndex() (index string, upToDate bool, err error) {
+	res, err := m.esClient.Indices.GetSettings(
+		m.esClient.Indices.GetSettings.WithIndex(utils.NEWS_INDEX),
+		m.esClient.Indices.GetSettings.WithContext(context.Background()),
 	)
 	if err != nil {
-		log.Fatalf("Failed to put mapping: %v", err)
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if res.IsError() {
+		return "", false, fmt.Errorf("%v", res)
+	}
+
+	var indices map[string]struct {
+		Settings struct {
+			Index struct {
+				Analysis struct {
+					Analyzer map[string]interface{} `json:"analyzer"`
+					Filter   map[string]interface{} `json:"filter"`
+				} `json:"analysis"`
+			} `json:"index"`
+		} `json:"settings"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return
+	}
+	if len(indices) != 1 {
+		return "", false, fmt.Errorf("%v resolves to %v indices", utils.NEWS_INDEX, len(indices))
+	}
+	for name, settings := range indices {
+		analysis := settings.Settings.Index.Analysis
+		_, hasAnalyzer := analysis.Analyzer[synonym_service.SEARCH_ANALYZER]
+		_, hasStopwords := analysis.Filter["news_stopwords"]
+		return name, hasAnalyzer && hasStopwords, nil
+	}
+	return
+}
+
+// createNewsIndex creates index with the current analysis settings and
+// mapping, optionally as the target of the news alias.
+func (m *Service) createNewsIndex(index string, withAlias bool) error {
+	var body map[string]interface{}
+	json.Unmarshal([]byte(analysisSettings()), &body)
+	var mapping map[string]interface{}
+	json.Unmarshal([]byte(newsMapping()), &mapping)
+	body["mappings"] = mapping
+	if withAlias {
+		body["aliases"] = map[string]interface{}{utils.NEWS_INDEX: map[string]interface{}{}}
+	}
+	data, _ := json.Marshal(body)
+
+	res, err := m.esClient.Indices.Create(
+		index,
+		m.esClient.Indices.Create.WithBody(bytes.NewReader(data)),
+		m.esClient.Indices.Create.WithContext(context.Background()),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("%v", res)
+	}
+	return nil
+}
+
+// reindexNews moves the news index to the current analysis settings without
+// downtime: a new index is created and filled from the old one while it keeps
+// serving, then the news alias is switched over in one step and the old index
+// removed. Writes made to the old index during the copy are not carried over,
+// so ingestion should be paused while it runs. If anything fails the old
+// index is left as it is.
+func (m *Service) reindexNews(oldIndex string) (err error) {
+	newIndex := newsIndexName()
+	log.Printf("Reindexing %v into %v for the current analysis settings", oldIndex, newIndex)
+	if err = m.createNewsIndex(newIndex, false); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			m.esClient.Indices.Delete([]string{newIndex})
+		}
+	}()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"source": map[string]interface{}{"index": oldIndex},
+		"dest":   map[string]interface{}{"index": newIndex},
+	})
+	res, err := m.esClient.Reindex(
+		bytes.NewReader(body),
+		m.esClient.Reindex.WithWaitForCompletion(true),
+		m.esClient.Reindex.WithRefresh(true),
+		m.esClient.Reindex.WithContext(context.Background()),
+	)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	var reindexed struct {
+		Failures []interface{} `json:"failures"`
+	}
+	if res.IsError() {
+		return fmt.Errorf("%v", res)
+	}
+	if err = json.NewDecoder(res.Body).Decode(&reindexed); err != nil {
+		return
+	}
+	if len(reindexed.Failures) > 0 {
+		return fmt.Errorf("reindex failed: %v", reindexed.Failures)
+	}
+
+	// remove_index drops the old index, and the alias with it, atomically
+	// with adding the alias to the new index. It also works when news is
+	// still a plain index rather than an alias.
+	body, _ = json.Marshal(map[string]interface{}{
+		"actions": []interface{}{
+			map[string]interface{}{"add": map[string]interface{}{"index": newIndex, "alias": utils.NEWS_INDEX}},
+			map[string]interface{}{"remove_index": map[string]interface{}{"index": oldIndex}},
+		},
+	})
+	aliasRes, err := m.esClient.Indices.UpdateAliases(
+		bytes.NewReader(body),
+		m.esClient.Indices.UpdateAliases.WithContext(context.Background()),
+	)
+	if err != nil {
+		return
+	}
+	defer aliasRes.Body.Close()
+	if aliasRes.IsError() {
+		return fmt.Errorf("%v", aliasRes)
+	}
+	log.Printf("%v now points to %v", utils.NEWS_INDEX, newIndex)
+	return nil
+}
+
+// languageSubfields returns one subfield per language with a built-in
+// analyzer, e.g. title.hi analyzed with the hindi analyzer.
+func languageSubfields() string {
+	languages := make([]string, 0, len(language_service.ANALYZERS))
+	for language := range language_service.ANALYZERS {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	subfields := make([]string, 0, len(languages))
+	for _, language := range languages {
+		subfields = append(subfields, fmt.Sprintf(`
+	                "%v": { "type": "text", "analyzer": "%v" }`, language, language_service.ANALYZERS[language]))
 	}
+	return strings.Join(subfields, ",")
 }
@@ -0,0 +1,24 @@
+package models
+
+// Category is a node of the category taxonomy. Slug is the canonical name
+// articles are tagged with; top level categories have no parent.
+type Category struct {
+	ID       uint64  `json:"id" gorm:"primary_key;not null;auto_increment"`
+	Slug     string  `json:"slug" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Name     string  `json:"name" gorm:"not null"`
+	ParentID *uint64 `json:"parent_id" gorm:"index"`
+}
+
+// CategoryAlias maps another spelling of a category, e.g. "tech" or
+// "Sci-Tech", to its canonical category.
+type CategoryAlias struct {
+	ID         uint64 `json:"id" gorm:"primary_key;not null;auto_increment"`
+	Alias      string `json:"alias" gorm:"type:varchar(100);uniqueIndex;not null"`
+	CategoryID uint64 `json:"category_id" gorm:"index;not null"`
+}
+
+// NewsCategory links an article to each canonical category it belongs to.
+type NewsCategory struct {
+	NewsID     uint64 `json:"news_id" gorm:"primaryKey"`
+	CategoryID uint64 `json:"category_id" gorm:"primaryKey;index"`
+}
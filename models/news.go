@@ -13,4 +13,6 @@ type News struct {
 	RelevanceScore  float64   `json:"relevance_score"`
 	Latitude        float64   `json:"latitude"`
 	Longitude       float64   `json:"longitude"`
+	Language        string    `json:"language"`
+	SourceID        *uint64   `json:"source_id" gorm:"index"`
 }
@@ -0,0 +1,13 @@
+package models
+
+// Source is a publisher articles come from. Credibility scales the search
+// score of its articles and disabled sources are hidden everywhere.
+type Source struct {
+	ID          uint64  `json:"id" gorm:"primary_key;not null;auto_increment"`
+	Name        string  `json:"name" gorm:"type:varchar(255);uniqueIndex;not null"`
+	Domain      string  `json:"domain" gorm:"type:varchar(255);index"`
+	Country     string  `json:"country" gorm:"type:varchar(2)"`
+	Language    string  `json:"language" gorm:"type:varchar(8)"`
+	Credibility float64 `json:"credibility" gorm:"not null;default:1"`
+	Enabled     bool    `json:"enabled" gorm:"not null;default:true"`
+}
@@ -3,11 +3,25 @@ package vm
 import "time"
 
 type NewsResponse struct {
-	Articles []News `json:"articles"`
+	Articles []News  `json:"articles"`
+	Facets   *Facets `json:"facets,omitempty"`
 	MetaResponse
 }
 
+type Facets struct {
+	Category       []FacetBucket `json:"category"`
+	Source         []FacetBucket `json:"source"`
+	PublicationDay []FacetBucket `json:"publication_day"`
+	RelevanceScore []FacetBucket `json:"relevance_score"`
+}
+
+type FacetBucket struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
 type News struct {
+	ID              uint64    `json:"id"`
 	Title           string    `json:"title"`
 	Description     string    `json:"description"`
 	Url             string    `json:"url"`
@@ -18,6 +32,35 @@ type News struct {
 	LLMSummary      string    `json:"llm_summary"`
 	Latitude        float64   `json:"latitude"`
 	Longitude       float64   `json:"longitude"`
+	Language        string    `json:"language,omitempty"`
+	Address
+	RecentActivityScore float64     `json:"recent_activity_score,omitempty"`
+	DistanceKm          *float64    `json:"distance_km,omitempty"`
+	LastEventTime       *time.Time  `json:"last_event_time,omitempty"`
+	Highlights          *Highlights `json:"highlights,omitempty"`
+}
+
+type SuggestResponse struct {
+	Titles     []string `json:"titles"`
+	Sources    []string `json:"sources"`
+	Categories []string `json:"categories"`
+}
+
+type GeoClusterResponse struct {
+	Zoom     int          `json:"zoom"`
+	Clusters []GeoCluster `json:"clusters"`
+}
+
+type GeoCluster struct {
+	Key        string `json:"key"`
+	Count      int64  `json:"count"`
+	Centroid   LatLon `json:"centroid"`
+	TopArticle *News  `json:"top_article,omitempty"`
+}
+
+type Highlights struct {
+	Title       []string `json:"title,omitempty"`
+	Description []string `json:"description,omitempty"`
 }
 
 type NewsRaw struct {
@@ -43,6 +86,22 @@ type NewsElastic struct {
 	Category        []string  `json:"category"`
 	RelevanceScore  float64   `json:"relevance_score"`
 	Location        LatLon    `json:"location"`
+	Language        string    `json:"language,omitempty"`
+	CategoryIDs     []uint64  `json:"category_ids,omitempty"`
+	SourceID        uint64    `json:"source_id,omitempty"`
+	Address
+	RecentActivityScore float64    `json:"recent_activity_score,omitempty"`
+	LastEventTime       *time.Time `json:"last_event_time,omitempty"`
+	Embedding           []float32  `json:"embedding,omitempty"`
+}
+
+// GeoInput is a location given as "lat,long", a geohash or a place name.
+type GeoInput string
+
+type Address struct {
+	City    string `json:"city,omitempty"`
+	Region  string `json:"region,omitempty"`
+	Country string `json:"country,omitempty"`
 }
 
 type LatLon struct {
@@ -51,19 +110,48 @@ type LatLon struct {
 }
 
 type FetchNewsRequest struct {
-	Category string  `uri:"category" json:"category,omitempty"`
-	Score    float64 `uri:"score" json:"score,omitempty"`
-	Source   string  `uri:"source" json:"source,omitempty"`
-	Lat      float64 `form:"lat" json:"lat,omitempty"`
-	Long     float64 `form:"long" json:"long,omitempty"`
-	Radius   int     `form:"radius" json:"radius,omitempty"`
-	Query    string  `form:"q" json:"query,omitempty"`
+	ID             uint64   `uri:"id" json:"id,omitempty"`
+	Category       string   `uri:"category" form:"category" json:"category,omitempty"`
+	Score          float64  `uri:"score" form:"score" json:"score,omitempty"`
+	Source         string   `uri:"source" form:"source" json:"source,omitempty"`
+	Country        string   `uri:"country" form:"country" json:"country,omitempty"`
+	Region         string   `uri:"region" form:"region" json:"region,omitempty"`
+	Lat            *float64 `form:"lat" json:"lat,omitempty"`
+	Long           *float64 `form:"long" json:"long,omitempty"`
+	Location       GeoInput `form:"location" json:"location,omitempty"`
+	Radius         float64  `form:"radius" json:"radius,omitempty"`
+	Unit           string   `form:"unit" json:"unit,omitempty"`
+	TopLeft        string   `form:"top_left" json:"top_left,omitempty"`
+	BottomRight    string   `form:"bottom_right" json:"bottom_right,omitempty"`
+	Polygon        string   `form:"polygon" json:"polygon,omitempty"`
+	Zoom           *int     `form:"zoom" json:"zoom,omitempty"`
+	Query          string   `form:"q" json:"query,omitempty"`
+	Mode           string   `form:"mode" json:"mode,omitempty"`
+	Lang           string   `form:"lang" json:"lang,omitempty"`
+	From           string   `form:"from" json:"from,omitempty"`
+	To             string   `form:"to" json:"to,omitempty"`
+	Since          string   `form:"since" json:"since,omitempty"`
+	Facets         bool     `form:"facets" json:"facets,omitempty"`
+	Highlight      bool     `form:"highlight" json:"highlight,omitempty"`
+	AutoCorrect    bool     `form:"autocorrect" json:"autocorrect,omitempty"`
+	Recency        bool     `form:"recency" json:"recency,omitempty"`
+	Sort           string   `form:"sort" json:"sort,omitempty"`
+	LocalityScale  *float64 `form:"locality_scale" json:"locality_scale,omitempty"`
+	RecencyScale   *float64 `form:"recency_scale" json:"recency_scale,omitempty"`
+	Decay          *float64 `form:"decay" json:"decay,omitempty"`
+	ActivityFactor *float64 `form:"activity_factor" json:"activity_factor,omitempty"`
 	PaginationRequest
 }
 
+const (
+	CURSOR_PAGING = "cursor"
+)
+
 type PaginationRequest struct {
-	PageNumber int64 `form:"p" json:"page_number,omitempty"`
-	Limit      int64 `form:"l" json:"limit,omitempty"`
+	PageNumber int64  `form:"p" json:"page_number,omitempty"`
+	Limit      int64  `form:"l" json:"limit,omitempty"`
+	Paging     string `form:"paging" json:"paging,omitempty"`
+	Cursor     string `form:"cursor" json:"cursor,omitempty"`
 }
 
 func NewPaginationRequest(p int64, l int64) PaginationRequest {
@@ -80,6 +168,12 @@ func (p PaginationRequest) GetPageNumber() int64 {
 	return p.PageNumber
 }
 
+// IsCursorMode reports whether the caller asked for search_after based
+// paging, either by passing paging=cursor or a cursor from a previous page.
+func (p PaginationRequest) IsCursorMode() bool {
+	return p.Cursor != "" || p.Paging == CURSOR_PAGING
+}
+
 func (p PaginationRequest) GetLimit() int64 {
 	if p.Limit <= 0 {
 		return 5
@@ -88,8 +182,12 @@ func (p PaginationRequest) GetLimit() int64 {
 }
 
 type MetaResponse struct {
-	TotalPages  int64                  `json:"total_pages"`
-	TotalRecord int64                  `json:"total_records"`
-	PageNumber  int64                  `json:"page_number"`
-	SearchQuery map[string]interface{} `json:"search_query"`
+	TotalPages     int64                  `json:"total_pages"`
+	TotalRecord    int64                  `json:"total_records"`
+	PageNumber     int64                  `json:"page_number"`
+	SearchQuery    map[string]interface{} `json:"search_query"`
+	NextCursor     string                 `json:"next_cursor,omitempty"`
+	DidYouMean     string                 `json:"did_you_mean,omitempty"`
+	CorrectedQuery string                 `json:"corrected_query,omitempty"`
+	Degraded       bool                   `json:"degraded,omitempty"`
 }
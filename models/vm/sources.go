@@ -0,0 +1,19 @@
+package vm
+
+import "news_service/models"
+
+type SourcesRequest struct{}
+
+type SourcesResponse struct {
+	Sources []models.Source `json:"sources"`
+}
+
+// UpdateSourceRequest changes the fields that are set and leaves the rest.
+type UpdateSourceRequest struct {
+	ID          uint64   `uri:"id"`
+	Enabled     *bool    `json:"enabled"`
+	Credibility *float64 `json:"credibility"`
+	Country     *string  `json:"country"`
+	Language    *string  `json:"language"`
+	Domain      *string  `json:"domain"`
+}
@@ -0,0 +1,26 @@
+package vm
+
+// SynonymRule is one line of a synonyms set in Solr format, e.g.
+// "pm, prime minister" or "ev => electric vehicle".
+type SynonymRule struct {
+	ID       string `json:"id,omitempty"`
+	Synonyms string `json:"synonyms"`
+}
+
+type SynonymsRequest struct {
+	Rules []SynonymRule `json:"rules"`
+}
+
+type SynonymsResponse struct {
+	Count int64         `json:"count"`
+	Rules []SynonymRule `json:"rules"`
+}
+
+type StopwordsRequest struct {
+	Stopwords []string `json:"stopwords"`
+}
+
+type StopwordsResponse struct {
+	Count     int64    `json:"count"`
+	Stopwords []string `json:"stopwords"`
+}
@@ -0,0 +1,56 @@
+package apis
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"news_service/services/source_service"
+	"news_service/services/synonym_service"
+	"news_service/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ADMIN_TOKEN_HEADER = "X-Admin-Token"
+
+	GetSynonyms        = "/synonyms"
+	UpdateSynonyms     = "/synonyms"
+	ReloadSynonymsFile = "/synonyms/reload"
+	GetStopwords       = "/stopwords"
+	UpdateStopwords    = "/stopwords"
+	ReloadStopwords    = "/stopwords/reload"
+	GetSources         = "/sources"
+	UpdateSource       = "/sources/:id"
+)
+
+func NewAdminController(engine *gin.Engine, synonymService *synonym_service.SynonymService,
+	sourceService *source_service.SourceService) {
+	router := engine.Group("/api/v1/admin", adminAuth(os.Getenv("ADMIN_TOKEN")))
+	router.GET(GetSynonyms, utils.Controller(utils.NewOptions(synonymService.GetSynonyms)))
+	router.PUT(UpdateSynonyms, utils.Controller(utils.NewOptions(synonymService.UpdateSynonyms).ForPost()))
+	router.POST(ReloadSynonymsFile, utils.Controller(utils.NewOptions(synonymService.ReloadSynonymsFile)))
+	router.GET(GetStopwords, utils.Controller(utils.NewOptions(synonymService.GetStopwords)))
+	router.PUT(UpdateStopwords, utils.Controller(utils.NewOptions(synonymService.UpdateStopwords).ForPost()))
+	router.POST(ReloadStopwords, utils.Controller(utils.NewOptions(synonymService.ReloadStopwordsFile)))
+	router.GET(GetSources, utils.Controller(utils.NewOptions(sourceService.GetSources)))
+	router.PATCH(UpdateSource, utils.Controller(utils.NewOptions(sourceService.UpdateSource).ForPost()))
+}
+
+// adminAuth only lets requests carrying token in X-Admin-Token through. When
+// no token is configured the admin APIs are disabled.
+func adminAuth(token string) gin.HandlerFunc {
+	if token == "" {
+		logrus.Warn("ADMIN_TOKEN is not set, admin APIs are disabled")
+	}
+	return func(ginCtx *gin.Context) {
+		given := ginCtx.GetHeader(ADMIN_TOKEN_HEADER)
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			ginCtx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		ginCtx.Next()
+	}
+}
@@ -8,20 +8,40 @@ import (
 )
 
 const (
+	GetNews                   = "/news"
 	GetNewsByCategory         = "/news/catorgory/:category"
 	GetNewsByScore            = "/news/score/:score"
 	GetNewsBySearch           = "/news/search"
+	GetNewsSuggestions        = "/news/suggest"
 	GetNewsBySource           = "/news/source/:source"
 	GetNewsByNearBy           = "/news/nearby"
+	GetNewsByRegion           = "/news/region/:country/:region"
+	GetNewsWithinArea         = "/news/within"
+	GetNewsClusters           = "/news/clusters"
+	GetNewsByID               = "/news/:id"
+	GetRelatedNews            = "/news/:id/related"
 	GetTrendingNewsByLocation = "/trending"
+	GetGlobalTrendingNews     = "/trending/global"
+	GetTrendingNewsByCategory = "/trending/category/:category"
+	GetTrendingNewsBySource   = "/trending/source/:source"
 )
 
 func NewNewsController(engine *gin.Engine, newsService *news_service.NewsService) {
 	router := engine.Group("/api/v1")
+	router.GET(GetNews, utils.Controller(utils.NewOptions(newsService.GetNews)))
 	router.GET(GetNewsByCategory, utils.Controller(utils.NewOptions(newsService.GetNewsByCategory)))
 	router.GET(GetNewsByScore, utils.Controller(utils.NewOptions(newsService.GetNewsByScore)))
 	router.GET(GetNewsBySearch, utils.Controller(utils.NewOptions(newsService.GetNewsBySearch)))
+	router.GET(GetNewsSuggestions, utils.Controller(utils.NewOptions(newsService.GetSuggestions)))
 	router.GET(GetNewsBySource, utils.Controller(utils.NewOptions(newsService.GetNewsBySource)))
 	router.GET(GetNewsByNearBy, utils.Controller(utils.NewOptions(newsService.GetNewsByLocation)))
+	router.GET(GetNewsByRegion, utils.Controller(utils.NewOptions(newsService.GetNewsByRegion)))
+	router.GET(GetNewsWithinArea, utils.Controller(utils.NewOptions(newsService.GetNewsWithinArea)))
+	router.GET(GetNewsClusters, utils.Controller(utils.NewOptions(newsService.GetNewsClusters)))
+	router.GET(GetNewsByID, utils.Controller(utils.NewOptions(newsService.GetNewsByID)))
+	router.GET(GetRelatedNews, utils.Controller(utils.NewOptions(newsService.GetRelatedNews)))
 	router.GET(GetTrendingNewsByLocation, utils.Controller(utils.NewOptions(newsService.GetTrendingNewsByLocation)))
+	router.GET(GetGlobalTrendingNews, utils.Controller(utils.NewOptions(newsService.GetGlobalTrendingNews)))
+	router.GET(GetTrendingNewsByCategory, utils.Controller(utils.NewOptions(newsService.GetTrendingNewsByCategory)))
+	router.GET(GetTrendingNewsBySource, utils.Controller(utils.NewOptions(newsService.GetTrendingNewsBySource)))
 }
@@ -12,8 +12,14 @@ import (
 	"news_service/models"
 	"news_service/models/vm"
 	"news_service/services/apis"
+	"news_service/services/category_service"
+	"news_service/services/embedding_service"
+	"news_service/services/geo_service"
+	"news_service/services/language_service"
 	"news_service/services/llm_service"
 	"news_service/services/news_service"
+	"news_service/services/source_service"
+	"news_service/services/synonym_service"
 	"news_service/utils"
 	"os"
 	"strings"
@@ -29,16 +35,31 @@ var buff chan models.UserActivity
 var syncMap sync.Map
 
 func PathHandler(backends utils.Backends) {
-	migrationService := migration.NewMigrationService(backends.MySQLConn, backends.EsClient)
+	elastic := utils.NewElastic(backends.EsClient)
+	synonymService := synonym_service.NewSynonymService(elastic, os.Getenv("SYNONYMS_FILE"), os.Getenv("STOPWORDS_FILE"))
+	migrationService := migration.NewMigrationService(backends.MySQLConn, backends.EsClient, synonymService)
 	go migrationService.InitMigration()
 	r = backends.GinEngine
 
-	elastic := utils.NewElastic(backends.EsClient)
 	llmService := llm_service.NewLlmService()
-	newsService := news_service.NewNewsService(backends.MySQLConn.DB, elastic, llmService)
+	gazetteer, err := geo_service.LoadGazetteer(os.Getenv("GAZETTEER_FILE"))
+	if err != nil {
+		logrus.Errorf("Failed to load gazetteer, place names will not resolve: %s", err)
+		gazetteer = geo_service.NewGazetteer()
+	}
+	if err = gazetteer.LoadAdmin1Names(os.Getenv("GAZETTEER_ADMIN1_FILE")); err != nil {
+		logrus.Errorf("Failed to load gazetteer regions, regions will be reported by code: %s", err)
+	}
+	geoService := geo_service.NewGeoService(gazetteer)
+	categoryService := category_service.NewCategoryService(backends.MySQLConn.DB)
+	sourceService := source_service.NewSourceService(backends.MySQLConn.DB)
+	embedder := embedding_service.NewHashingEmbedder(embedding_service.EMBEDDING_DIMS)
+	newsService := news_service.NewNewsService(backends.MySQLConn.DB, elastic, llmService, geoService,
+		categoryService, sourceService, embedder, news_service.LoadTrendingConfig())
 	apis.NewNewsController(r, newsService)
+	apis.NewAdminController(r, synonymService, sourceService)
 
-	// readDataFromFile(backends)
+	// readDataFromFile(backends, geoService, categoryService, sourceService, embedder)
 	// createNewUsers(backends)
 	// go updateElasticIndex(backends)
 	// go updateElasticIndex(backends)
@@ -46,7 +67,9 @@ func PathHandler(backends utils.Backends) {
 	// generateUserActivityEvents(backends)
 }
 
-func readDataFromFile(backends utils.Backends) {
+func readDataFromFile(backends utils.Backends, geoService *geo_service.GeoService,
+	categoryService *category_service.CategoryService, sourceService *source_service.SourceService,
+	embedder embedding_service.Embedder) {
 	file, err := os.Open("news_data.json")
 	if err != nil {
 		logrus.Fatalf("Failed to open file: %s", err)
@@ -60,18 +83,31 @@ func readDataFromFile(backends utils.Backends) {
 	}
 
 	dbNewsArray := []models.News{}
+	categoryIDs := [][]uint64{}
 	for _, newsRaw := range newsRawArray {
 		t, _ := time.Parse("2006-01-02T15:04:05", newsRaw.PublicationDate)
+		categories, ids := normalizeCategories(categoryService, newsRaw.Category)
+		categoryIDs = append(categoryIDs, ids)
+		language := language_service.Detect(newsRaw.Title + " " + newsRaw.Description)
+		sourceName := newsRaw.SourceName
+		var sourceID *uint64
+		if source, err := sourceService.FindOrCreate(newsRaw.SourceName, newsRaw.Url, language); err == nil {
+			sourceName, sourceID = source.Name, &source.ID
+		} else {
+			logrus.Errorf("Failed to register source %v: %s", newsRaw.SourceName, err)
+		}
 		dbNewsArray = append(dbNewsArray, models.News{
 			Title:           newsRaw.Title,
 			Description:     newsRaw.Title,
 			Url:             newsRaw.Url,
 			PublicationDate: t,
-			SourceName:      newsRaw.SourceName,
-			Category:        strings.Join(newsRaw.Category, ","),
+			SourceName:      sourceName,
+			SourceID:        sourceID,
+			Category:        strings.Join(categories, ","),
 			RelevanceScore:  newsRaw.RelevanceScore,
 			Latitude:        newsRaw.Latitude,
 			Longitude:       newsRaw.Longitude,
+			Language:        language,
 		})
 	}
 
@@ -81,7 +117,20 @@ func readDataFromFile(backends utils.Backends) {
 		return
 	}
 
-	for _, dbNews := range dbNewsArray {
+	newsCategories := []models.NewsCategory{}
+	for i, dbNews := range dbNewsArray {
+		for _, id := range categoryIDs[i] {
+			newsCategories = append(newsCategories, models.NewsCategory{NewsID: dbNews.ID, CategoryID: id})
+		}
+	}
+	if len(newsCategories) > 0 {
+		if err = backends.MySQLConn.DB.CreateInBatches(&newsCategories, 100).Error; err != nil {
+			logrus.Fatal(err)
+			return
+		}
+	}
+
+	for i, dbNews := range dbNewsArray {
 		newsElastic := vm.NewsElastic{
 			ID:              dbNews.ID,
 			Title:           dbNews.Title,
@@ -91,11 +140,19 @@ func readDataFromFile(backends utils.Backends) {
 			SourceName:      dbNews.SourceName,
 			Category:        strings.Split(dbNews.Category, ","),
 			RelevanceScore:  dbNews.RelevanceScore,
+			Language:        dbNews.Language,
+			CategoryIDs:     categoryIDs[i],
+			SourceID:        sourceIDValue(dbNews.SourceID),
 			Location: vm.LatLon{
 				Lat: dbNews.Latitude,
 				Lon: dbNews.Longitude,
 			},
 		}
+		newsElastic.Address = geoService.ReverseGeocode(newsElastic.Location)
+		newsElastic.Embedding, err = embedder.Embed(newsElastic.Title + " " + newsElastic.Description)
+		if err != nil {
+			logrus.Errorf("Error embedding document %v: %s", newsElastic.ID, err)
+		}
 
 		data, _ := json.Marshal(newsElastic)
 		res, err := backends.EsClient.Index(
@@ -110,6 +167,36 @@ func readDataFromFile(backends utils.Backends) {
 	}
 }
 
+// normalizeCategories maps raw categories to the slugs and IDs of their
+// canonical categories. Categories missing from the taxonomy are kept
+// lowercased without an ID.
+func normalizeCategories(categoryService *category_service.CategoryService, raw []string) (slugs []string, ids []uint64) {
+	seen := map[string]bool{}
+	for _, r := range raw {
+		slug := strings.ToLower(strings.Trim(r, " "))
+		category, ok := categoryService.Normalize(r)
+		if ok {
+			slug = category.Slug
+		}
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+		if ok {
+			ids = append(ids, category.ID)
+		}
+	}
+	return
+}
+
+func sourceIDValue(id *uint64) uint64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
 func createNewUsers(backends utils.Backends) {
 	users := make([]models.User, 0)
 	for i := 1; i <= 10; i++ {
@@ -0,0 +1,155 @@
+package category_service
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"news_service/models"
+	"news_service/utils"
+
+	"gorm.io/gorm"
+)
+
+// taxonomy is an in-memory copy of the categories and category_aliases tables.
+type taxonomy struct {
+	byID     map[uint64]models.Category
+	byName   map[string]uint64
+	children map[uint64][]uint64
+}
+
+type CategoryService struct {
+	db       *gorm.DB
+	taxonomy *utils.TTLCache[*taxonomy]
+}
+
+func NewCategoryService(db *gorm.DB) *CategoryService {
+	c := &CategoryService{db: db}
+	c.taxonomy = utils.NewTTLCache("categories", utils.CACHE_TTL, c.load, emptyTaxonomy)
+	return c
+}
+
+// Normalize maps a raw category, its slug or any alias to the canonical
+// category, ignoring case and extra spaces.
+func (c *CategoryService) Normalize(raw string) (category models.Category, ok bool) {
+	t := c.taxonomy.Get()
+	id, ok := t.byName[utils.NormalizeName(raw)]
+	if !ok {
+		return
+	}
+	return t.byID[id], true
+}
+
+// Descendants returns category followed by all categories below it.
+func (c *CategoryService) Descendants(category models.Category) []models.Category {
+	t := c.taxonomy.Get()
+	descendants := []models.Category{category}
+	visited := map[uint64]bool{category.ID: true}
+	for i := 0; i < len(descendants); i++ {
+		for _, childID := range t.children[descendants[i].ID] {
+			if visited[childID] {
+				continue
+			}
+			visited[childID] = true
+			descendants = append(descendants, t.byID[childID])
+		}
+	}
+	return descendants
+}
+
+// Names returns the normalized slug, name and aliases of category.
+func (c *CategoryService) Names(category models.Category) []string {
+	names := make([]string, 0)
+	for name, id := range c.taxonomy.Get().byName {
+		if id == category.ID {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *CategoryService) load() (t *taxonomy, err error) {
+	categories := make([]models.Category, 0)
+	if err = c.db.Find(&categories).Error; err != nil {
+		return
+	}
+	aliases := make([]models.CategoryAlias, 0)
+	if err = c.db.Find(&aliases).Error; err != nil {
+		return
+	}
+
+	t = emptyTaxonomy()
+	for _, category := range categories {
+		t.byID[category.ID] = category
+		t.byName[utils.NormalizeName(category.Slug)] = category.ID
+		t.byName[utils.NormalizeName(category.Name)] = category.ID
+		if category.ParentID != nil {
+			t.children[*category.ParentID] = append(t.children[*category.ParentID], category.ID)
+		}
+	}
+	for _, alias := range aliases {
+		if _, ok := t.byID[alias.CategoryID]; ok {
+			t.byName[utils.NormalizeName(alias.Alias)] = alias.CategoryID
+		}
+	}
+	return
+}
+
+func emptyTaxonomy() *taxonomy {
+	return &taxonomy{
+		byID:     make(map[uint64]models.Category),
+		byName:   make(map[string]uint64),
+		children: make(map[uint64][]uint64),
+	}
+}
+
+// SeedCategory is a node of the JSON file the taxonomy is seeded from.
+type SeedCategory struct {
+	Slug     string         `json:"slug"`
+	Name     string         `json:"name"`
+	Aliases  []string       `json:"aliases"`
+	Children []SeedCategory `json:"children"`
+}
+
+// SeedCategories fills the categories tables from path when they are empty.
+func SeedCategories(db *gorm.DB, path string) (err error) {
+	var count int64
+	if err = db.Model(&models.Category{}).Count(&count).Error; err != nil || count > 0 {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	seeds := make([]SeedCategory, 0)
+	if err = json.Unmarshal(data, &seeds); err != nil {
+		return
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return seedCategories(tx, seeds, nil)
+	})
+}
+
+func seedCategories(tx *gorm.DB, seeds []SeedCategory, parentID *uint64) error {
+	for _, seed := range seeds {
+		category := models.Category{
+			Slug:     utils.NormalizeName(seed.Slug),
+			Name:     seed.Name,
+			ParentID: parentID,
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		for _, alias := range seed.Aliases {
+			if err := tx.Create(&models.CategoryAlias{Alias: utils.NormalizeName(alias), CategoryID: category.ID}).Error; err != nil {
+				return err
+			}
+		}
+		if err := seedCategories(tx, seed.Children, &category.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package embedding_service
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// EMBEDDING_DIMS is the size of the vectors stored in the embedding field of
+// the news index. Changing it requires reindexing.
+const EMBEDDING_DIMS = 256
+
+// Embedder turns text into a vector for semantic search. Implementations must
+// return vectors of Dimensions() length, or nil when the text has nothing to
+// embed.
+type Embedder interface {
+	Embed(text string) ([]float32, error)
+	Dimensions() int
+}
+
+// HashingEmbedder is a deterministic embedder that needs no model or network.
+// Words and adjacent word pairs are hashed into a fixed number of buckets,
+// so texts sharing vocabulary end up close in cosine similarity.
+type HashingEmbedder struct {
+	dims int
+}
+
+func NewHashingEmbedder(dims int) *HashingEmbedder {
+	if dims <= 0 {
+		dims = EMBEDDING_DIMS
+	}
+	return &HashingEmbedder{dims: dims}
+}
+
+func (h *HashingEmbedder) Dimensions() int {
+	return h.dims
+}
+
+func (h *HashingEmbedder) Embed(text string) ([]float32, error) {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	counts := make(map[string]int)
+	for i, token := range tokens {
+		counts[token]++
+		if i > 0 {
+			counts[tokens[i-1]+" "+token]++
+		}
+	}
+
+	vector := make([]float64, h.dims)
+	for feature, count := range counts {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(feature))
+		sum := hasher.Sum64()
+		// The top bit picks the sign so colliding features tend to cancel
+		// out instead of piling up in the same bucket.
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1.0
+		}
+		vector[sum%uint64(h.dims)] += sign * (1 + math.Log(float64(count)))
+	}
+
+	norm := 0.0
+	for _, value := range vector {
+		norm += value * value
+	}
+	if norm == 0 {
+		return nil, nil
+	}
+	norm = math.Sqrt(norm)
+
+	embedding := make([]float32, h.dims)
+	for i, value := range vector {
+		embedding[i] = float32(value / norm)
+	}
+	return embedding, nil
+}
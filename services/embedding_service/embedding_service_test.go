@@ -0,0 +1,38 @@
+package embedding_service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHashingEmbedderEmbed(t *testing.T) {
+	embedder := NewHashingEmbedder(0)
+	if embedder.Dimensions() != EMBEDDING_DIMS {
+		t.Fatalf("Dimensions() = %v, want %v", embedder.Dimensions(), EMBEDDING_DIMS)
+	}
+
+	first, err := embedder.Embed("Monsoon floods hit Mumbai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := embedder.Embed("monsoon  FLOODS hit mumbai!")
+	if len(first) != EMBEDDING_DIMS {
+		t.Fatalf("len = %v, want %v", len(first), EMBEDDING_DIMS)
+	}
+	norm := 0.0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("embedding differs at %v: %v != %v", i, first[i], second[i])
+		}
+		norm += float64(first[i]) * float64(first[i])
+	}
+	if math.Abs(norm-1) > 1e-5 {
+		t.Errorf("squared norm = %v, want 1", norm)
+	}
+
+	for _, text := range []string{"", "   ", "?!"} {
+		if embedding, _ := embedder.Embed(text); embedding != nil {
+			t.Errorf("Embed(%q) = %v, want nil", text, embedding)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package geo_service
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"news_service/models/vm"
+	"news_service/utils"
+)
+
+// Column positions in a GeoNames cities dump (e.g. cities15000.txt).
+const (
+	NAME_COLUMN            = 1
+	ASCII_NAME_COLUMN      = 2
+	ALTERNATE_NAMES_COLUMN = 3
+	LATITUDE_COLUMN        = 4
+	LONGITUDE_COLUMN       = 5
+	COUNTRY_CODE_COLUMN    = 8
+	ADMIN1_CODE_COLUMN     = 10
+	POPULATION_COLUMN      = 14
+	GEONAMES_COLUMNS       = 15
+
+	// Columns of a GeoNames admin1CodesASCII.txt file.
+	ADMIN1_KEY_COLUMN  = 0
+	ADMIN1_NAME_COLUMN = 1
+	ADMIN1_COLUMNS     = 2
+)
+
+type Place struct {
+	Name        string
+	CountryCode string
+	Admin1Code  string
+	Population  int64
+	Location    vm.LatLon
+}
+
+// Gazetteer is an in-memory index of places loaded from a GeoNames dump.
+type Gazetteer struct {
+	places      []Place
+	byName      map[string]int
+	admin1Names map[string]string
+}
+
+func NewGazetteer() *Gazetteer {
+	return &Gazetteer{
+		places:      make([]Place, 0),
+		byName:      make(map[string]int),
+		admin1Names: make(map[string]string),
+	}
+}
+
+// LoadGazetteer reads a tab separated GeoNames cities file. Lines that cannot
+// be parsed are skipped.
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gazetteer := NewGazetteer()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		columns := strings.Split(scanner.Text(), "\t")
+		if len(columns) < GEONAMES_COLUMNS {
+			continue
+		}
+		lat, err := strconv.ParseFloat(columns[LATITUDE_COLUMN], 64)
+		if err != nil {
+			continue
+		}
+		lon, err := strconv.ParseFloat(columns[LONGITUDE_COLUMN], 64)
+		if err != nil {
+			continue
+		}
+		population, _ := strconv.ParseInt(columns[POPULATION_COLUMN], 10, 64)
+
+		names := []string{columns[NAME_COLUMN], columns[ASCII_NAME_COLUMN]}
+		if columns[ALTERNATE_NAMES_COLUMN] != "" {
+			names = append(names, strings.Split(columns[ALTERNATE_NAMES_COLUMN], ",")...)
+		}
+		gazetteer.add(Place{
+			Name:        columns[NAME_COLUMN],
+			CountryCode: columns[COUNTRY_CODE_COLUMN],
+			Admin1Code:  columns[ADMIN1_CODE_COLUMN],
+			Population:  population,
+			Location:    vm.LatLon{Lat: lat, Lon: lon},
+		}, names)
+	}
+	return gazetteer, scanner.Err()
+}
+
+// LoadAdmin1Names reads a GeoNames admin1CodesASCII file so regions can be
+// reported by name instead of by code.
+func (g *Gazetteer) LoadAdmin1Names(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		columns := strings.Split(scanner.Text(), "\t")
+		if len(columns) < ADMIN1_COLUMNS {
+			continue
+		}
+		g.admin1Names[columns[ADMIN1_KEY_COLUMN]] = columns[ADMIN1_NAME_COLUMN]
+	}
+	return scanner.Err()
+}
+
+// RegionName returns the admin1 name of place, falling back to its code.
+func (g *Gazetteer) RegionName(place Place) string {
+	if name, ok := g.admin1Names[place.CountryCode+"."+place.Admin1Code]; ok {
+		return name
+	}
+	return place.Admin1Code
+}
+
+// Nearest returns the place closest to the given point within maxKm.
+func (g *Gazetteer) Nearest(lat float64, lon float64, maxKm float64) (place Place, ok bool) {
+	nearest := maxKm
+	for _, p := range g.places {
+		if distance := DistanceKm(lat, lon, p.Location.Lat, p.Location.Lon); distance <= nearest {
+			place, nearest, ok = p, distance, true
+		}
+	}
+	return
+}
+
+// add indexes place under each of its names. When two places share a name
+// the more populous one wins.
+func (g *Gazetteer) add(place Place, names []string) {
+	g.places = append(g.places, place)
+	index := len(g.places) - 1
+	for _, name := range names {
+		key := utils.NormalizeName(name)
+		if key == "" {
+			continue
+		}
+		if existing, ok := g.byName[key]; ok && g.places[existing].Population >= place.Population {
+			continue
+		}
+		g.byName[key] = index
+	}
+}
+
+// Lookup finds a place by its name, ASCII name or any alternate name.
+func (g *Gazetteer) Lookup(name string) (place Place, ok bool) {
+	index, ok := g.byName[utils.NormalizeName(name)]
+	if !ok {
+		return
+	}
+	return g.places[index], true
+}
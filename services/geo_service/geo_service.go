@@ -0,0 +1,113 @@
+package geo_service
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"news_service/models/vm"
+)
+
+const (
+	EARTH_RADIUS_KM        = 6371.0088
+	MAX_REVERSE_GEOCODE_KM = 50
+)
+
+var (
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+	ErrUnknownLocation    = errors.New("unknown location")
+)
+
+type GeoService struct {
+	gazetteer *Gazetteer
+}
+
+func NewGeoService(gazetteer *Gazetteer) *GeoService {
+	if gazetteer == nil {
+		gazetteer = NewGazetteer()
+	}
+	return &GeoService{
+		gazetteer: gazetteer,
+	}
+}
+
+// DistanceKm returns the great-circle distance between two points, matching
+// the "arc" distance Elasticsearch sorts nearby results by.
+func DistanceKm(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	distance := 2 * EARTH_RADIUS_KM * math.Asin(math.Min(1, math.Sqrt(a)))
+	return math.Round(distance*1000) / 1000
+}
+
+func ValidLatLon(lat float64, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// ParseLatLon parses a "lat,long" pair and checks it is within range.
+func ParseLatLon(value string) (latLon vm.LatLon, err error) {
+	latString, lonString, ok := strings.Cut(value, ",")
+	if !ok {
+		return latLon, ErrInvalidCoordinates
+	}
+	if latLon.Lat, err = strconv.ParseFloat(strings.Trim(latString, " "), 64); err != nil {
+		return latLon, ErrInvalidCoordinates
+	}
+	if latLon.Lon, err = strconv.ParseFloat(strings.Trim(lonString, " "), 64); err != nil {
+		return latLon, ErrInvalidCoordinates
+	}
+	if !ValidLatLon(latLon.Lat, latLon.Lon) {
+		return latLon, ErrInvalidCoordinates
+	}
+	return
+}
+
+// Resolve turns a geo input into coordinates. It accepts "lat,long", a place
+// name known to the gazetteer or a geohash, in that order, since short place
+// names can also be valid geohashes.
+func (g *GeoService) Resolve(input vm.GeoInput) (latLon vm.LatLon, err error) {
+	value := strings.Trim(string(input), " ")
+	if value == "" {
+		return latLon, ErrUnknownLocation
+	}
+	if strings.Contains(value, ",") {
+		if latLon, err = ParseLatLon(value); err == nil {
+			return
+		}
+	}
+	if place, ok := g.gazetteer.Lookup(value); ok {
+		return place.Location, nil
+	}
+	if latLon, err = DecodeGeohash(value); err == nil {
+		return
+	}
+	return latLon, ErrUnknownLocation
+}
+
+// Geocode looks up a place name in the gazetteer only. Unlike Resolve it
+// never reads free text as coordinates or a geohash.
+func (g *GeoService) Geocode(name string) (latLon vm.LatLon, err error) {
+	place, ok := g.gazetteer.Lookup(name)
+	if !ok {
+		return latLon, ErrUnknownLocation
+	}
+	return place.Location, nil
+}
+
+// ReverseGeocode returns the city, region and country of the closest known
+// place, or an empty address when nothing is within MAX_REVERSE_GEOCODE_KM.
+func (g *GeoService) ReverseGeocode(latLon vm.LatLon) (address vm.Address) {
+	place, ok := g.gazetteer.Nearest(latLon.Lat, latLon.Lon, MAX_REVERSE_GEOCODE_KM)
+	if !ok {
+		return
+	}
+	return vm.Address{
+		City:    place.Name,
+		Region:  g.gazetteer.RegionName(place),
+		Country: place.CountryCode,
+	}
+}
@@ -0,0 +1,56 @@
+package geo_service
+
+import (
+	"errors"
+	"strings"
+
+	"news_service/models/vm"
+)
+
+const (
+	GEOHASH_ALPHABET   = "0123456789bcdefghjkmnpqrstuvwxyz"
+	MAX_GEOHASH_LENGTH = 12
+)
+
+var ErrInvalidGeohash = errors.New("invalid geohash")
+
+// DecodeGeohash returns the centre of the cell described by hash.
+func DecodeGeohash(hash string) (latLon vm.LatLon, err error) {
+	hash = strings.ToLower(hash)
+	if hash == "" || len(hash) > MAX_GEOHASH_LENGTH {
+		return latLon, ErrInvalidGeohash
+	}
+
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	evenBit := true
+	for _, c := range hash {
+		index := strings.IndexRune(GEOHASH_ALPHABET, c)
+		if index < 0 {
+			return latLon, ErrInvalidGeohash
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := index&(1<<bit) != 0
+			if evenBit {
+				mid := (lonRange[0] + lonRange[1]) / 2
+				if set {
+					lonRange[0] = mid
+				} else {
+					lonRange[1] = mid
+				}
+			} else {
+				mid := (latRange[0] + latRange[1]) / 2
+				if set {
+					latRange[0] = mid
+				} else {
+					latRange[1] = mid
+				}
+			}
+			evenBit = !evenBit
+		}
+	}
+
+	latLon.Lat = (latRange[0] + latRange[1]) / 2
+	latLon.Lon = (lonRange[0] + lonRange[1]) / 2
+	return
+}
@@ -0,0 +1,84 @@
+package language_service
+
+import (
+	"unicode"
+)
+
+// Language codes (ISO 639-1) the detector can return.
+const (
+	ENGLISH   = "en"
+	HINDI     = "hi"
+	BENGALI   = "bn"
+	TAMIL     = "ta"
+	TELUGU    = "te"
+	GUJARATI  = "gu"
+	PUNJABI   = "pa"
+	KANNADA   = "kn"
+	MALAYALAM = "ml"
+	ODIA      = "or"
+	URDU      = "ur"
+)
+
+// ANALYZERS maps the languages that have a built-in Elasticsearch analyzer
+// to it. Other languages are only searched through the standard analyzer.
+var ANALYZERS = map[string]string{
+	ENGLISH: "english",
+	HINDI:   "hindi",
+	BENGALI: "bengali",
+}
+
+// Each script is mostly used by one language in Indian news, so the script
+// decides the language. Devanagari is read as Hindi, Arabic as Urdu and
+// Latin as English.
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Latin, ENGLISH},
+	{unicode.Devanagari, HINDI},
+	{unicode.Bengali, BENGALI},
+	{unicode.Tamil, TAMIL},
+	{unicode.Telugu, TELUGU},
+	{unicode.Gujarati, GUJARATI},
+	{unicode.Gurmukhi, PUNJABI},
+	{unicode.Kannada, KANNADA},
+	{unicode.Malayalam, MALAYALAM},
+	{unicode.Oriya, ODIA},
+	{unicode.Arabic, URDU},
+}
+
+// NATIVE_SCRIPT_WEIGHT is how much more a character of a non-Latin script
+// counts than a Latin one. Headlines in Indian languages often carry English
+// words, while English articles rarely contain other scripts.
+const NATIVE_SCRIPT_WEIGHT = 2
+
+// Detect returns the language of the script text is mostly written in, or an
+// empty string when text has no letters of a known script. Vowel signs and
+// other marks count towards their script, as Indic words are largely made of
+// them.
+func Detect(text string) string {
+	counts := make([]int, len(scripts))
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) {
+			continue
+		}
+		for i, script := range scripts {
+			if unicode.Is(script.table, r) {
+				if script.table == unicode.Latin {
+					counts[i]++
+				} else {
+					counts[i] += NATIVE_SCRIPT_WEIGHT
+				}
+				break
+			}
+		}
+	}
+
+	language, best := "", 0
+	for i, count := range counts {
+		if count > best {
+			language, best = scripts[i].language, count
+		}
+	}
+	return language
+}
@@ -0,0 +1,23 @@
+package language_service
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Election results announced", ENGLISH},
+		{"चुनाव के नतीजे घोषित", HINDI},
+		{"चुनाव results", HINDI},
+		{"IPL 2025: কলকাতার জয়", BENGALI},
+		{"Modi visits Chennai, says வணக்கம்", ENGLISH},
+		{"2025 !!", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := Detect(test.text); got != test.want {
+			t.Errorf("Detect(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package llm_service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"news_service/utils"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/genai"
@@ -12,6 +14,9 @@ import (
 
 const (
 	GEMINI_API_KEY = "GEMINI_API_KEY" //Put Gemini API key here
+
+	// LLM_TIMEOUT bounds every call so a slow model cannot hold up a request.
+	LLM_TIMEOUT = 5 * time.Second
 )
 
 type LlmService struct{}
@@ -38,16 +43,20 @@ func (l *LlmService) AnalyzeQuery(ctx *utils.Context, query string) (out *LlmOut
 	Example: {"entities": ["News18"], "intent": ["category"]}
 	User Query: "%s"`, query)
 
-	client, err := genai.NewClient(ctx.Ctx, &genai.ClientConfig{
+	llmCtx, cancel := context.WithTimeout(ctx.Ctx, LLM_TIMEOUT)
+	defer cancel()
+
+	client, err := genai.NewClient(llmCtx, &genai.ClientConfig{
 		APIKey:  GEMINI_API_KEY,
 		Backend: genai.BackendGeminiAPI,
 	})
 	if err != nil {
-		log.Fatal(err)
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
 	}
 
 	result, err := client.Models.GenerateContent(
-		ctx.Ctx,
+		llmCtx,
 		"gemini-2.5-flash",
 		genai.Text(prompt),
 		nil,
@@ -64,6 +73,9 @@ func (l *LlmService) AnalyzeQuery(ctx *utils.Context, query string) (out *LlmOut
 			fmt.Errorf("JSON parse error: %v\nRaw: %s", err, result.Text()))
 		return nil, err
 	}
+	if out == nil {
+		return nil, errors.New("empty query analysis")
+	}
 
 	return
 }
@@ -75,7 +87,10 @@ func (l *LlmService) GenerateSummary(ctx *utils.Context, articles []string) (sum
 	Also, use escape character if required.
 	Articles: "%v"`, articles)
 
-	client, err := genai.NewClient(ctx.Ctx, &genai.ClientConfig{
+	llmCtx, cancel := context.WithTimeout(ctx.Ctx, LLM_TIMEOUT)
+	defer cancel()
+
+	client, err := genai.NewClient(llmCtx, &genai.ClientConfig{
 		APIKey:  GEMINI_API_KEY,
 		Backend: genai.BackendGeminiAPI,
 	})
@@ -85,7 +100,7 @@ func (l *LlmService) GenerateSummary(ctx *utils.Context, articles []string) (sum
 	}
 
 	result, err := client.Models.GenerateContent(
-		ctx.Ctx,
+		llmCtx,
 		"gemini-2.5-flash",
 		genai.Text(prompt),
 		nil,
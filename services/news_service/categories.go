@@ -0,0 +1,50 @@
+package news_service
+
+import (
+	"strings"
+)
+
+// categoryTreeFilter matches articles in category or any category below it,
+// so "sports" also returns cricket and football articles. Aliases such as
+// "sport" resolve to the same category. Articles indexed before the taxonomy
+// existed have no category_ids, so their raw category is matched against the
+// slug, name and aliases of every category in the tree.
+func (n *NewsService) categoryTreeFilter(category string) map[string]interface{} {
+	canonical, ok := n.categoryService.Normalize(category)
+	if !ok {
+		return categoryFilter(strings.ToLower(category))
+	}
+
+	ids := make([]uint64, 0)
+	slugs := make([]string, 0)
+	should := make([]interface{}, 0)
+	seen := make(map[string]bool)
+	for _, c := range n.categoryService.Descendants(canonical) {
+		ids = append(ids, c.ID)
+		slugs = append(slugs, c.Slug)
+		for _, name := range n.categoryService.Names(c) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			should = append(should, map[string]interface{}{
+				"match_phrase": map[string]interface{}{"category": name},
+			})
+		}
+	}
+	should = append(should,
+		map[string]interface{}{
+			"terms": map[string]interface{}{"category_ids": ids},
+		},
+		map[string]interface{}{
+			"terms": map[string]interface{}{"category.keyword": slugs},
+		},
+		categoryFilter(strings.ToLower(category)),
+	)
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should":               should,
+			"minimum_should_match": 1,
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package news_service
+
+import (
+	"errors"
+	"net/http"
+
+	"news_service/models/vm"
+	"news_service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	GEO_CLUSTERS         = "clusters"
+	GEO_CLUSTER_CENTROID = "centroid"
+	GEO_CLUSTER_TOP_HIT  = "top_article"
+	DEFAULT_ZOOM         = 6
+	MAX_ZOOM             = 29
+	MAX_GEO_CLUSTERS     = 1000
+)
+
+// GetNewsClusters groups the articles in the viewport into map tiles for the
+// given zoom level, returning the count, centroid and most relevant article
+// of each tile.
+func (n *NewsService) GetNewsClusters(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.GeoClusterResponse, werr utils.WrapperError) {
+	zoom := DEFAULT_ZOOM
+	if request.Zoom != nil {
+		zoom = *request.Zoom
+	}
+	if zoom < 0 || zoom > MAX_ZOOM {
+		logrus.WithContext(ctx.Ctx).Error("invalid zoom")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid zoom"))
+		return
+	}
+	if werr = n.resolveLocation(ctx, &request); werr != nil {
+		return
+	}
+
+	area, err := areaFilter(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+	filters, err := n.buildFilters(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+	common, err := n.requestFilters(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": append(append(filters, area), common...),
+			},
+		},
+		"aggs": map[string]interface{}{
+			GEO_CLUSTERS: map[string]interface{}{
+				"geotile_grid": map[string]interface{}{
+					"field":     "location",
+					"precision": zoom,
+					"size":      MAX_GEO_CLUSTERS,
+				},
+				"aggs": map[string]interface{}{
+					GEO_CLUSTER_CENTROID: map[string]interface{}{
+						"geo_centroid": map[string]interface{}{
+							"field": "location",
+						},
+					},
+					GEO_CLUSTER_TOP_HIT: map[string]interface{}{
+						"top_hits": map[string]interface{}{
+							"size":    1,
+							"_source": sourceExcludes(),
+							"sort": []interface{}{
+								map[string]interface{}{
+									"relevance_score": map[string]interface{}{
+										"order": "desc",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, vm.NewPaginationRequest(1, 1))
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	aggregations, ok := elasticResponse["aggregations"].(map[string]interface{})
+	if !ok {
+		logrus.WithContext(ctx.Ctx).Errorf("error from elastic: %v", elasticResponse)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+
+	response.Zoom = zoom
+	response.Clusters = mapGeoClusters(aggregations[GEO_CLUSTERS])
+	return
+}
+
+func mapGeoClusters(aggregation interface{}) []vm.GeoCluster {
+	clusters := make([]vm.GeoCluster, 0)
+	agg, _ := aggregation.(map[string]interface{})
+	buckets, _ := agg["buckets"].([]interface{})
+	for _, b := range buckets {
+		bucket, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, _ := bucket["key"].(string)
+		count, _ := bucket["doc_count"].(float64)
+		cluster := vm.GeoCluster{
+			Key:   key,
+			Count: int64(count),
+		}
+
+		centroid, _ := bucket[GEO_CLUSTER_CENTROID].(map[string]interface{})
+		location, _ := centroid["location"].(map[string]interface{})
+		cluster.Centroid.Lat, _ = location["lat"].(float64)
+		cluster.Centroid.Lon, _ = location["lon"].(float64)
+
+		topHit, _ := bucket[GEO_CLUSTER_TOP_HIT].(map[string]interface{})
+		hits, _ := topHit["hits"].(map[string]interface{})
+		hitList, _ := hits["hits"].([]interface{})
+		if len(hitList) > 0 {
+			if hit, ok := hitList[0].(map[string]interface{}); ok {
+				news := toNews(toNewsElastic(hit))
+				cluster.TopArticle = &news
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
@@ -0,0 +1,88 @@
+package news_service
+
+import (
+	"fmt"
+
+	"news_service/models/vm"
+)
+
+const (
+	CATEGORY_FACET        = "category"
+	SOURCE_FACET          = "source"
+	PUBLICATION_DAY_FACET = "publication_day"
+	RELEVANCE_SCORE_FACET = "relevance_score"
+	FACET_SIZE            = 20
+)
+
+func facetAggregations() map[string]interface{} {
+	return map[string]interface{}{
+		CATEGORY_FACET: map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "category.keyword",
+				"size":  FACET_SIZE,
+			},
+		},
+		SOURCE_FACET: map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "source_name.keyword",
+				"size":  FACET_SIZE,
+			},
+		},
+		PUBLICATION_DAY_FACET: map[string]interface{}{
+			"date_histogram": map[string]interface{}{
+				"field":             "publication_date",
+				"calendar_interval": "day",
+				"format":            DATE_LAYOUT_ES,
+				"min_doc_count":     1,
+				"order": map[string]interface{}{
+					"_key": "desc",
+				},
+			},
+		},
+		RELEVANCE_SCORE_FACET: map[string]interface{}{
+			"range": map[string]interface{}{
+				"field": "relevance_score",
+				"ranges": []interface{}{
+					map[string]interface{}{"key": "0-0.25", "to": 0.25},
+					map[string]interface{}{"key": "0.25-0.5", "from": 0.25, "to": 0.5},
+					map[string]interface{}{"key": "0.5-0.75", "from": 0.5, "to": 0.75},
+					map[string]interface{}{"key": "0.75-1", "from": 0.75},
+				},
+			},
+		},
+	}
+}
+
+func mapFacets(aggregations map[string]interface{}) *vm.Facets {
+	return &vm.Facets{
+		Category:       mapFacetBuckets(aggregations[CATEGORY_FACET]),
+		Source:         mapFacetBuckets(aggregations[SOURCE_FACET]),
+		PublicationDay: mapFacetBuckets(aggregations[PUBLICATION_DAY_FACET]),
+		RelevanceScore: mapFacetBuckets(aggregations[RELEVANCE_SCORE_FACET]),
+	}
+}
+
+func mapFacetBuckets(aggregation interface{}) []vm.FacetBucket {
+	facetBuckets := make([]vm.FacetBucket, 0)
+	agg, ok := aggregation.(map[string]interface{})
+	if !ok {
+		return facetBuckets
+	}
+	buckets, _ := agg["buckets"].([]interface{})
+	for _, b := range buckets {
+		bucket, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, ok := bucket["key_as_string"].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", bucket["key"])
+		}
+		count, _ := bucket["doc_count"].(float64)
+		facetBuckets = append(facetBuckets, vm.FacetBucket{
+			Key:   key,
+			Count: int64(count),
+		})
+	}
+	return facetBuckets
+}
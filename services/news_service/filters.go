@@ -0,0 +1,293 @@
+package news_service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"news_service/models/vm"
+)
+
+const (
+	DATE_LAYOUT    = "2006-01-02"
+	DATE_LAYOUT_ES = "yyyy-MM-dd"
+	DEFAULT_RADIUS = 10
+	KILOMETERS     = "km"
+	RECENCY_SCALE  = "7d"
+	RECENCY_OFFSET = "1d"
+)
+
+// sinceRegex matches relative windows such as 30m, 24h, 7d or 2w.
+var sinceRegex = regexp.MustCompile(`^[1-9][0-9]*[mhdw]$`)
+
+func languageFilter(lang string) map[string]interface{} {
+	return map[string]interface{}{
+		"term": map[string]interface{}{
+			"language": strings.ToLower(lang),
+		},
+	}
+}
+
+func categoryFilter(category string) map[string]interface{} {
+	return map[string]interface{}{
+		"term": map[string]interface{}{
+			"category": category,
+		},
+	}
+}
+
+func scoreFilter(score float64) map[string]interface{} {
+	return map[string]interface{}{
+		"range": map[string]interface{}{
+			"relevance_score": map[string]interface{}{
+				"gte": score,
+			},
+		},
+	}
+}
+
+func sourceFilter(source string) map[string]interface{} {
+	return map[string]interface{}{
+		"match_phrase": map[string]interface{}{
+			"source_name": source,
+		},
+	}
+}
+
+func countryFilter(country string) map[string]interface{} {
+	return map[string]interface{}{
+		"term": map[string]interface{}{
+			"country": strings.ToUpper(country),
+		},
+	}
+}
+
+// regionFilter matches the whole region name, so "Bengal" does not match
+// "West Bengal". Region names are stored as the gazetteer spells them, so
+// only spacing is normalized and case is ignored.
+func regionFilter(region string) map[string]interface{} {
+	return map[string]interface{}{
+		"term": map[string]interface{}{
+			"region.keyword": map[string]interface{}{
+				"value":            strings.Join(strings.Fields(region), " "),
+				"case_insensitive": true,
+			},
+		},
+	}
+}
+
+func geoDistanceFilter(lat float64, long float64, distance string) map[string]interface{} {
+	return map[string]interface{}{
+		"geo_distance": map[string]interface{}{
+			"distance": distance,
+			"location": map[string]float64{
+				"lat": lat,
+				"lon": long,
+			},
+		},
+	}
+}
+
+// radiusUnits maps the accepted radius units to Elasticsearch distance units.
+var radiusUnits = map[string]string{
+	"km":         "km",
+	"kilometers": "km",
+	"m":          "m",
+	"meters":     "m",
+	"mi":         "mi",
+	"miles":      "mi",
+}
+
+// radiusDistance formats the radius of the request as an Elasticsearch
+// distance, defaulting to DEFAULT_RADIUS kilometers.
+func radiusDistance(request vm.FetchNewsRequest) (distance string, err error) {
+	if request.Radius <= 0 {
+		return fmt.Sprintf("%v%v", DEFAULT_RADIUS, KILOMETERS), nil
+	}
+	unit := KILOMETERS
+	if request.Unit != "" {
+		var ok bool
+		if unit, ok = radiusUnits[strings.ToLower(request.Unit)]; !ok {
+			return "", errors.New("invalid unit")
+		}
+	}
+	return strconv.FormatFloat(request.Radius, 'f', -1, 64) + unit, nil
+}
+
+// publicationDateFilter accepts RFC3339 timestamps or plain dates; a plain
+// "to" date includes the whole day.
+func publicationDateFilter(from string, to string) (filter map[string]interface{}, err error) {
+	dateRange := map[string]interface{}{}
+	if from != "" {
+		fromTime, _, err := parseDate(from)
+		if err != nil {
+			return nil, errors.New("invalid from date")
+		}
+		dateRange["gte"] = fromTime.Format(time.RFC3339)
+	}
+	if to != "" {
+		toTime, dateOnly, err := parseDate(to)
+		if err != nil {
+			return nil, errors.New("invalid to date")
+		}
+		if dateOnly {
+			dateRange["lt"] = toTime.AddDate(0, 0, 1).Format(time.RFC3339)
+		} else {
+			dateRange["lte"] = toTime.Format(time.RFC3339)
+		}
+	}
+	filter = map[string]interface{}{
+		"range": map[string]interface{}{
+			"publication_date": dateRange,
+		},
+	}
+	return
+}
+
+func parseDate(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse(time.RFC3339, value); err == nil {
+		return
+	}
+	t, err = time.Parse(DATE_LAYOUT, value)
+	dateOnly = err == nil
+	return
+}
+
+// hasLocation reports whether the request carries a point, which
+// resolveLocation guarantees has both coordinates.
+func hasLocation(request vm.FetchNewsRequest) bool {
+	return request.Lat != nil && request.Long != nil
+}
+
+// buildFilters composes every filter set on the request into bool filter clauses.
+func (n *NewsService) buildFilters(request vm.FetchNewsRequest) (filters []interface{}, err error) {
+	filters = make([]interface{}, 0)
+	if category := strings.Trim(request.Category, " "); category != "" {
+		filters = append(filters, n.categoryTreeFilter(category))
+	}
+	if source := strings.Trim(request.Source, " "); source != "" {
+		filters = append(filters, sourceFilter(source))
+	}
+	if country := strings.Trim(request.Country, " "); country != "" {
+		filters = append(filters, countryFilter(country))
+	}
+	if region := strings.Trim(request.Region, " "); region != "" {
+		filters = append(filters, regionFilter(region))
+	}
+	if request.Score < 0 {
+		return nil, errors.New("invalid score")
+	}
+	if request.Score > 0 {
+		filters = append(filters, scoreFilter(request.Score))
+	}
+	if hasLocation(request) {
+		distance, err := radiusDistance(request)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, geoDistanceFilter(*request.Lat, *request.Long, distance))
+	}
+	return
+}
+
+// requestFilters restricts results to the publication date window and the
+// language of the request, and leaves out disabled sources. It applies to
+// every list endpoint through searchNews.
+func (n *NewsService) requestFilters(request vm.FetchNewsRequest) (filters []interface{}, err error) {
+	filters = make([]interface{}, 0)
+	if sourceFilter := n.disabledSourceFilter(); sourceFilter != nil {
+		filters = append(filters, sourceFilter)
+	}
+	if lang := strings.Trim(request.Lang, " "); lang != "" {
+		filters = append(filters, languageFilter(lang))
+	}
+	if request.From != "" || request.To != "" {
+		dateFilter, err := publicationDateFilter(request.From, request.To)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, dateFilter)
+	}
+	if request.Since != "" {
+		if !sinceRegex.MatchString(request.Since) {
+			return nil, errors.New("invalid since")
+		}
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{
+				"publication_date": map[string]interface{}{
+					"gte": "now-" + request.Since,
+				},
+			},
+		})
+	}
+	return
+}
+
+// withFilters adds filter clauses to query without changing how it scores.
+func withFilters(query map[string]interface{}, filters []interface{}) {
+	if len(filters) == 0 {
+		return
+	}
+	must, ok := query["query"]
+	if !ok {
+		must = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	query["query"] = map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   must,
+			"filter": filters,
+		},
+	}
+}
+
+// withRecencyDecay scales the score of query down the older an article is.
+func withRecencyDecay(query map[string]interface{}) {
+	inner, ok := query["query"]
+	if !ok {
+		inner = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	query["query"] = map[string]interface{}{
+		"function_score": map[string]interface{}{
+			"query": inner,
+			"functions": []interface{}{
+				map[string]interface{}{
+					"gauss": map[string]interface{}{
+						"publication_date": map[string]interface{}{
+							"origin": "now",
+							"scale":  RECENCY_SCALE,
+							"offset": RECENCY_OFFSET,
+							"decay":  0.5,
+						},
+					},
+				},
+			},
+			"boost_mode": "multiply",
+		},
+	}
+}
+
+func publicationDateSort() map[string]interface{} {
+	return map[string]interface{}{
+		"publication_date": map[string]interface{}{
+			"order": "desc",
+		},
+	}
+}
+
+func geoDistanceSort(lat float64, long float64) map[string]interface{} {
+	return map[string]interface{}{
+		"_geo_distance": map[string]interface{}{
+			"location": map[string]float64{
+				"lat": lat,
+				"lon": long,
+			},
+			"order":           "asc",
+			"unit":            "km",
+			"distance_type":   "arc",
+			"ignore_unmapped": true,
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package news_service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegionFilter(t *testing.T) {
+	tests := []struct {
+		region string
+		want   string
+	}{
+		// Bengal is a suffix of West Bengal and must only match itself.
+		{"Bengal", "Bengal"},
+		{"West Bengal", "West Bengal"},
+		{"  west   bengal ", "west bengal"},
+	}
+	for _, test := range tests {
+		want := map[string]interface{}{
+			"term": map[string]interface{}{
+				"region.keyword": map[string]interface{}{
+					"value":            test.want,
+					"case_insensitive": true,
+				},
+			},
+		}
+		if got := regionFilter(test.region); !reflect.DeepEqual(got, want) {
+			t.Errorf("regionFilter(%q) = %v, want %v", test.region, got, want)
+		}
+	}
+}
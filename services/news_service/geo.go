@@ -0,0 +1,133 @@
+package news_service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"news_service/models/vm"
+	"news_service/services/geo_service"
+	"news_service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	MAX_POLYGON_POINTS = 500
+)
+
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+func boundingBoxFilter(topLeft vm.LatLon, bottomRight vm.LatLon) map[string]interface{} {
+	return map[string]interface{}{
+		"geo_bounding_box": map[string]interface{}{
+			"location": map[string]interface{}{
+				"top_left":     topLeft,
+				"bottom_right": bottomRight,
+			},
+		},
+	}
+}
+
+// parsePolygon validates a GeoJSON polygon. Coordinates are [lon, lat] pairs
+// and every ring has to be closed.
+func parsePolygon(value string) (polygon geoJSONPolygon, err error) {
+	if err = json.Unmarshal([]byte(value), &polygon); err != nil {
+		return polygon, errors.New("invalid polygon")
+	}
+	if !strings.EqualFold(polygon.Type, "Polygon") || len(polygon.Coordinates) == 0 {
+		return polygon, errors.New("invalid polygon")
+	}
+	points := 0
+	for _, ring := range polygon.Coordinates {
+		points += len(ring)
+		if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+			return polygon, errors.New("invalid polygon")
+		}
+		for _, point := range ring {
+			if !geo_service.ValidLatLon(point[1], point[0]) {
+				return polygon, errors.New("invalid polygon")
+			}
+		}
+	}
+	if points > MAX_POLYGON_POINTS {
+		return polygon, errors.New("polygon has too many points")
+	}
+	return
+}
+
+func polygonFilter(polygon geoJSONPolygon) map[string]interface{} {
+	return map[string]interface{}{
+		"geo_shape": map[string]interface{}{
+			"location": map[string]interface{}{
+				"shape": map[string]interface{}{
+					"type":        "polygon",
+					"coordinates": polygon.Coordinates,
+				},
+				"relation": "intersects",
+			},
+		},
+	}
+}
+
+// areaFilter builds the filter for the bounding box or polygon of the request.
+func areaFilter(request vm.FetchNewsRequest) (filter map[string]interface{}, err error) {
+	if request.Polygon != "" {
+		polygon, err := parsePolygon(request.Polygon)
+		if err != nil {
+			return nil, err
+		}
+		return polygonFilter(polygon), nil
+	}
+	if request.TopLeft == "" || request.BottomRight == "" {
+		return nil, errors.New("either polygon or top_left and bottom_right are required")
+	}
+	topLeft, err := geo_service.ParseLatLon(request.TopLeft)
+	if err != nil {
+		return nil, errors.New("invalid top_left")
+	}
+	bottomRight, err := geo_service.ParseLatLon(request.BottomRight)
+	if err != nil {
+		return nil, errors.New("invalid bottom_right")
+	}
+	if topLeft.Lat < bottomRight.Lat {
+		return nil, errors.New("top_left must be north of bottom_right")
+	}
+	return boundingBoxFilter(topLeft, bottomRight), nil
+}
+
+// resolveLocation fills Lat/Long from the location geo input when given and
+// validates the resulting coordinates. A point is only used when both lat and
+// long are given, so 0,0 is a valid location.
+func (n *NewsService) resolveLocation(ctx *utils.Context, request *vm.FetchNewsRequest) (werr utils.WrapperError) {
+	if request.Location != "" {
+		latLon, err := n.geoService.Resolve(request.Location)
+		if err != nil {
+			logrus.WithContext(ctx.Ctx).Error(err)
+			werr = utils.NewWrapperError(http.StatusBadRequest, err)
+			return
+		}
+		request.Lat = &latLon.Lat
+		request.Long = &latLon.Lon
+	}
+	if (request.Lat == nil) != (request.Long == nil) {
+		logrus.WithContext(ctx.Ctx).Error("lat and long must be given together")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("lat and long must be given together"))
+		return
+	}
+	if request.Lat != nil && (*request.Lat < -90 || *request.Lat > 90) {
+		logrus.WithContext(ctx.Ctx).Error("invalid latitude")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid latitude"))
+		return
+	}
+	if request.Long != nil && (*request.Long < -180 || *request.Long > 180) {
+		logrus.WithContext(ctx.Ctx).Error("invalid longitude")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid longitude"))
+		return
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package news_service
+
+import (
+	"news_service/models/vm"
+)
+
+const (
+	HIGHLIGHT_PRE_TAG        = "<em>"
+	HIGHLIGHT_POST_TAG       = "</em>"
+	HIGHLIGHT_FRAGMENT_SIZE  = 150
+	HIGHLIGHT_FRAGMENT_COUNT = 3
+)
+
+// highlightQuery returns the whole title and the best matching description
+// fragments with matched terms wrapped in <em> tags.
+func highlightQuery() map[string]interface{} {
+	return map[string]interface{}{
+		"pre_tags":  []string{HIGHLIGHT_PRE_TAG},
+		"post_tags": []string{HIGHLIGHT_POST_TAG},
+		"fields": map[string]interface{}{
+			"title": map[string]interface{}{
+				"number_of_fragments": 0,
+			},
+			"description": map[string]interface{}{
+				"fragment_size":       HIGHLIGHT_FRAGMENT_SIZE,
+				"number_of_fragments": HIGHLIGHT_FRAGMENT_COUNT,
+			},
+		},
+	}
+}
+
+func mapHighlights(hit map[string]interface{}) *vm.Highlights {
+	highlight, ok := hit["highlight"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return &vm.Highlights{
+		Title:       mapFragments(highlight["title"]),
+		Description: mapFragments(highlight["description"]),
+	}
+}
+
+func mapFragments(value interface{}) []string {
+	fragments, _ := value.([]interface{})
+	result := make([]string, 0, len(fragments))
+	for _, fragment := range fragments {
+		if f, ok := fragment.(string); ok {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package news_service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"news_service/models/vm"
+	"news_service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RRF_RANK_CONSTANT dampens the weight of top ranks, 60 being the value
+	// suggested by the original reciprocal rank fusion paper.
+	RRF_RANK_CONSTANT = 60
+	// MAX_HYBRID_WINDOW caps how many hits each side fetches, which limits
+	// how deep hybrid results can be paged.
+	MAX_HYBRID_WINDOW  = 500
+	MIN_NUM_CANDIDATES = 100
+)
+
+// getNewsByHybridSearch runs a BM25 search and a kNN search on the embedding
+// field and merges them with reciprocal rank fusion. The fusion is done here
+// rather than with the rrf retriever of Elasticsearch, which needs a paid
+// license. Both sides fetch every hit up to the requested page and the fused
+// ranking decides the order, so cursor paging and sort are not supported.
+func (n *NewsService) getNewsByHybridSearch(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if request.IsCursorMode() {
+		logrus.WithContext(ctx.Ctx).Error("cursor paging is not supported in hybrid mode")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("cursor paging is not supported in hybrid mode"))
+		return
+	}
+	if request.Sort != "" {
+		logrus.WithContext(ctx.Ctx).Error("sort is not supported in hybrid mode")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("sort is not supported in hybrid mode"))
+		return
+	}
+	window := request.GetPageNumber() * request.GetLimit()
+	if window > MAX_HYBRID_WINDOW {
+		logrus.WithContext(ctx.Ctx).Error("page is too deep for hybrid mode")
+		werr = utils.NewWrapperError(http.StatusBadRequest,
+			fmt.Errorf("hybrid mode returns at most %v results", MAX_HYBRID_WINDOW))
+		return
+	}
+
+	windowRequest := request
+	windowRequest.PaginationRequest = vm.PaginationRequest{PageNumber: 1, Limit: window}
+
+	lexicalQuery := n.searchQuery(request.Query, nil, nil)
+	n.withCredibility(lexicalQuery)
+	lexicalResponse, werr := n.searchNews(ctx, lexicalQuery, windowRequest)
+	if werr != nil {
+		return
+	}
+	if _, ok := lexicalResponse["hits"].(map[string]interface{}); !ok {
+		logrus.WithContext(ctx.Ctx).Errorf("error from elastic: %v", lexicalResponse)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	lexicalHits := hitList(lexicalResponse)
+
+	semanticHits := make([]interface{}, 0)
+	vector, err := n.embedder.Embed(request.Query)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+	}
+	if len(vector) > 0 {
+		semanticResponse, werr := n.knnSearch(ctx, vector, windowRequest)
+		if werr != nil {
+			return response, werr
+		}
+		semanticHits = hitList(semanticResponse)
+	}
+
+	fused := reciprocalRankFusion(lexicalHits, semanticHits)
+	total := int64(len(fused))
+	if lexicalTotal := totalHits(lexicalResponse); lexicalTotal > total {
+		total = lexicalTotal
+	}
+	start := min((request.GetPageNumber()-1)*request.GetLimit(), int64(len(fused)))
+	end := min(start+request.GetLimit(), int64(len(fused)))
+
+	// Reuse the lexical response so facets and other metadata are kept.
+	lexicalResponse["hits"] = map[string]interface{}{
+		"total": map[string]interface{}{"value": float64(total)},
+		"hits":  fused[start:end],
+	}
+
+	err = n.mapResponse(ctx, lexicalResponse, request, &response)
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
+		return
+	}
+
+	return
+}
+
+// knnSearch finds the articles whose embedding is closest to vector, applying
+// the same date and language filters as the lexical side.
+func (n *NewsService) knnSearch(ctx *utils.Context, vector []float32, request vm.FetchNewsRequest) (elasticResponse map[string]interface{}, werr utils.WrapperError) {
+	filters, err := n.requestFilters(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+
+	k := request.GetLimit()
+	numCandidates := k * 2
+	if numCandidates < MIN_NUM_CANDIDATES {
+		numCandidates = MIN_NUM_CANDIDATES
+	}
+	knn := map[string]interface{}{
+		"field":          "embedding",
+		"query_vector":   vector,
+		"k":              k,
+		"num_candidates": numCandidates,
+	}
+	if len(filters) > 0 {
+		knn["filter"] = filters
+	}
+	query := map[string]interface{}{
+		"knn":     knn,
+		"_source": sourceExcludes(),
+	}
+
+	elasticResponse, err = n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	if _, ok := elasticResponse["hits"].(map[string]interface{}); !ok {
+		logrus.WithContext(ctx.Ctx).Errorf("error from elastic: %v", elasticResponse)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	return
+}
+
+// reciprocalRankFusion scores every hit by the sum of 1/(k+rank) over the
+// rankings it appears in. Hits from the first ranking are kept when a
+// document appears in both, so their highlights survive.
+func reciprocalRankFusion(rankings ...[]interface{}) []interface{} {
+	scores := make(map[string]float64)
+	hits := make(map[string]interface{})
+	order := make([]string, 0)
+	for _, ranking := range rankings {
+		for rank, h := range ranking {
+			hit, _ := h.(map[string]interface{})
+			id, _ := hit["_id"].(string)
+			if id == "" {
+				continue
+			}
+			if _, ok := hits[id]; !ok {
+				hits[id] = hit
+				order = append(order, id)
+			}
+			scores[id] += 1.0 / float64(RRF_RANK_CONSTANT+rank+1)
+		}
+	}
+
+	fused := make([]interface{}, 0, len(order))
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+	for _, id := range order {
+		hit := hits[id].(map[string]interface{})
+		hit["_score"] = scores[id]
+		fused = append(fused, hit)
+	}
+	return fused
+}
+
+func hitList(elasticResponse map[string]interface{}) []interface{} {
+	hits, _ := elasticResponse["hits"].(map[string]interface{})
+	hitList, _ := hits["hits"].([]interface{})
+	return hitList
+}
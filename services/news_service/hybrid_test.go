@@ -0,0 +1,41 @@
+package news_service
+
+import "testing"
+
+func hits(ids ...string) []interface{} {
+	list := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, map[string]interface{}{"_id": id})
+	}
+	return list
+}
+
+func TestReciprocalRankFusion(t *testing.T) {
+	// b and c appear in both rankings and must outrank a and d, which are
+	// first in only one of them.
+	fused := reciprocalRankFusion(hits("a", "b", "c"), hits("d", "c", "b"))
+	want := []string{"b", "c", "a", "d"}
+	if len(fused) != len(want) {
+		t.Fatalf("got %v hits, want %v", len(fused), len(want))
+	}
+	for i, id := range want {
+		hit := fused[i].(map[string]interface{})
+		if hit["_id"] != id {
+			t.Errorf("rank %v = %v, want %v", i, hit["_id"], id)
+		}
+	}
+
+	both := 1.0/float64(RRF_RANK_CONSTANT+2) + 1.0/float64(RRF_RANK_CONSTANT+3)
+	if score := fused[0].(map[string]interface{})["_score"]; score != both {
+		t.Errorf("score = %v, want %v", score, both)
+	}
+}
+
+func TestReciprocalRankFusionEmpty(t *testing.T) {
+	if fused := reciprocalRankFusion(hits("a"), nil); len(fused) != 1 {
+		t.Errorf("got %v hits, want 1", len(fused))
+	}
+	if fused := reciprocalRankFusion(nil, nil); len(fused) != 0 {
+		t.Errorf("got %v hits, want 0", len(fused))
+	}
+}
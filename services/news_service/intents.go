@@ -0,0 +1,88 @@
+package news_service
+
+import (
+	"fmt"
+
+	"news_service/models/vm"
+	"news_service/services/llm_service"
+)
+
+// Intents the LLM extracts from a search query.
+const (
+	LATEST_INTENT   = "latest"
+	CATEGORY_INTENT = "category"
+	SOURCE_INTENT   = "source"
+	NEARBY_INTENT   = "nearby"
+
+	ENTITY_PHRASE_BOOST = 2
+	NEARBY_SCALE_KM     = 50
+	NEARBY_WEIGHT       = 5
+)
+
+func hasIntent(llmOutput *llm_service.LlmOutput, intent string) bool {
+	if llmOutput == nil {
+		return false
+	}
+	for _, i := range llmOutput.Intent {
+		if i == intent {
+			return true
+		}
+	}
+	return false
+}
+
+// nearbyLocation returns the point a nearby intent refers to: the caller's
+// location when one is given, otherwise the first entity the gazetteer knows.
+func (n *NewsService) nearbyLocation(request vm.FetchNewsRequest, llmOutput *llm_service.LlmOutput) *vm.LatLon {
+	if !hasIntent(llmOutput, NEARBY_INTENT) {
+		return nil
+	}
+	if hasLocation(request) {
+		return &vm.LatLon{Lat: *request.Lat, Lon: *request.Long}
+	}
+	for _, entity := range llmOutput.Entities {
+		if latLon, err := n.geoService.Geocode(entity); err == nil {
+			return &latLon
+		}
+	}
+	return nil
+}
+
+// entityPhraseQueries boosts articles that mention an entity as a whole
+// phrase, so "Narendra Modi" ranks above articles with either word alone.
+func entityPhraseQueries(entities []string) []interface{} {
+	queries := make([]interface{}, 0, len(entities))
+	for _, entity := range entities {
+		queries = append(queries, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  entity,
+				"fields": searchFields(),
+				"type":   "phrase",
+				"boost":  ENTITY_PHRASE_BOOST,
+			},
+		})
+	}
+	return queries
+}
+
+// nearbyFunction scores articles higher the closer they are to location.
+// Being a score function it reorders results without filtering any out. The
+// exists filter stops articles without a location from getting the full boost.
+func nearbyFunction(location vm.LatLon) map[string]interface{} {
+	return map[string]interface{}{
+		"filter": map[string]interface{}{
+			"exists": map[string]interface{}{"field": "location"},
+		},
+		"gauss": map[string]interface{}{
+			"location": map[string]interface{}{
+				"origin": map[string]float64{
+					"lat": location.Lat,
+					"lon": location.Lon,
+				},
+				"scale": fmt.Sprintf("%vkm", NEARBY_SCALE_KM),
+				"decay": 0.5,
+			},
+		},
+		"weight": NEARBY_WEIGHT,
+	}
+}
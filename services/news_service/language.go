@@ -0,0 +1,23 @@
+package news_service
+
+import (
+	"sort"
+
+	"news_service/services/language_service"
+)
+
+// searchFields lists title and description together with their
+// per-language subfields, so a query is analyzed the way each article was.
+func searchFields() []string {
+	languages := make([]string, 0, len(language_service.ANALYZERS))
+	for language := range language_service.ANALYZERS {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	fields := []string{"title^3", "description"}
+	for _, language := range languages {
+		fields = append(fields, "title."+language+"^3", "description."+language)
+	}
+	return fields
+}
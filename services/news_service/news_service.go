@@ -6,55 +6,84 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
+	"news_service/models"
 	"news_service/models/vm"
+	"news_service/services/category_service"
+	"news_service/services/embedding_service"
+	"news_service/services/geo_service"
 	"news_service/services/llm_service"
+	"news_service/services/source_service"
 	"news_service/utils"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// Modes of the search API.
+const (
+	DEFAULT_SEARCH_MODE  = ""
+	LEXICAL_SEARCH_MODE  = "lexical"
+	ADVANCED_SEARCH_MODE = "advanced"
+	HYBRID_SEARCH_MODE   = "hybrid"
+)
+
 type NewsService struct {
-	db         *gorm.DB
-	elastic    *utils.Elastic
-	llmService *llm_service.LlmService
+	db              *gorm.DB
+	elastic         *utils.Elastic
+	llmService      *llm_service.LlmService
+	geoService      *geo_service.GeoService
+	categoryService *category_service.CategoryService
+	sourceService   *source_service.SourceService
+	embedder        embedding_service.Embedder
+	trending        TrendingConfig
 }
 
-func NewNewsService(db *gorm.DB, elastic *utils.Elastic, llmService *llm_service.LlmService) *NewsService {
+func NewNewsService(db *gorm.DB, elastic *utils.Elastic, llmService *llm_service.LlmService,
+	geoService *geo_service.GeoService, categoryService *category_service.CategoryService,
+	sourceService *source_service.SourceService, embedder embedding_service.Embedder, trending TrendingConfig) *NewsService {
 	return &NewsService{
-		db:         db,
-		elastic:    elastic,
-		llmService: llmService,
+		db:              db,
+		elastic:         elastic,
+		llmService:      llmService,
+		geoService:      geoService,
+		categoryService: categoryService,
+		sourceService:   sourceService,
+		embedder:        embedder,
+		trending:        trending,
 	}
 }
 
-func (n *NewsService) GetNewsByCategory(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
-	if strings.Trim(request.Category, " ") == "" {
-		logrus.WithContext(ctx.Ctx).Error("invalid category")
-		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid category"))
+// GetNews applies any combination of category, source, score, location and
+// publication date filters in a single query.
+func (n *NewsService) GetNews(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if werr = n.resolveLocation(ctx, &request); werr != nil {
+		return
+	}
+
+	filters, err := n.buildFilters(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
 		return
 	}
 
+	sort := []interface{}{publicationDateSort()}
+	if hasLocation(request) {
+		sort = []interface{}{geoDistanceSort(*request.Lat, *request.Long), publicationDateSort()}
+	}
+
 	query := map[string]interface{}{
 		"query": map[string]interface{}{
-			"term": map[string]interface{}{
-				"category": request.Category,
-			},
-		},
-		"sort": []interface{}{
-			map[string]interface{}{
-				"publication_date": map[string]interface{}{
-					"order": "desc",
-				},
+			"bool": map[string]interface{}{
+				"filter": filters,
 			},
 		},
+		"sort": sort,
 	}
 
-	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
-	if err != nil {
-		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
 		return
 	}
 
@@ -67,6 +96,32 @@ func (n *NewsService) GetNewsByCategory(ctx *utils.Context, request vm.FetchNews
 	return
 }
 
+func (n *NewsService) GetNewsByCategory(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if strings.Trim(request.Category, " ") == "" {
+		logrus.WithContext(ctx.Ctx).Error("invalid category")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid category"))
+		return
+	}
+
+	query := map[string]interface{}{
+		"query": n.categoryTreeFilter(request.Category),
+		"sort":  []interface{}{publicationDateSort()},
+	}
+
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
+		return
+	}
+
+	err := n.mapResponse(ctx, elasticResponse, request, &response)
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
+		return
+	}
+
+	return
+}
+
 func (n *NewsService) GetNewsByScore(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
 	if request.Score <= 0 {
 		logrus.WithContext(ctx.Ctx).Error("invalid score")
@@ -74,13 +129,7 @@ func (n *NewsService) GetNewsByScore(ctx *utils.Context, request vm.FetchNewsReq
 		return
 	}
 	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"range": map[string]interface{}{
-				"relevance_score": map[string]interface{}{
-					"gte": request.Score,
-				},
-			},
-		},
+		"query": scoreFilter(request.Score),
 		"sort": []interface{}{
 			map[string]interface{}{
 				"relevance_score": map[string]interface{}{
@@ -89,14 +138,32 @@ func (n *NewsService) GetNewsByScore(ctx *utils.Context, request vm.FetchNewsReq
 			},
 		},
 	}
+	if request.Recency {
+		query = map[string]interface{}{
+			"query": map[string]interface{}{
+				"function_score": map[string]interface{}{
+					"query": scoreFilter(request.Score),
+					"functions": []interface{}{
+						map[string]interface{}{
+							"field_value_factor": map[string]interface{}{
+								"field":   "relevance_score",
+								"missing": 0,
+							},
+						},
+					},
+					"boost_mode": "replace",
+				},
+			},
+		}
+		withRecencyDecay(query)
+	}
 
-	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
-	if err != nil {
-		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
 		return
 	}
 
-	err = n.mapResponse(ctx, elasticResponse, request, &response)
+	err := n.mapResponse(ctx, elasticResponse, request, &response)
 	if err != nil {
 		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
 		return
@@ -110,24 +177,87 @@ func (n *NewsService) GetNewsBySource(ctx *utils.Context, request vm.FetchNewsRe
 		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid source"))
 		return
 	}
+	query := map[string]interface{}{
+		"query": sourceFilter(request.Source),
+		"sort":  []interface{}{publicationDateSort()},
+	}
+
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
+		return
+	}
+
+	err := n.mapResponse(ctx, elasticResponse, request, &response)
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
+		return
+	}
+	return
+}
+
+// GetNewsByRegion returns articles reverse geocoded to the given country code
+// and region at ingestion.
+func (n *NewsService) GetNewsByRegion(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if strings.Trim(request.Country, " ") == "" {
+		logrus.WithContext(ctx.Ctx).Error("invalid country")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid country"))
+		return
+	}
+	if strings.Trim(request.Region, " ") == "" {
+		logrus.WithContext(ctx.Ctx).Error("invalid region")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid region"))
+		return
+	}
+
 	query := map[string]interface{}{
 		"query": map[string]interface{}{
-			"match_phrase": map[string]interface{}{
-				"source_name": request.Source,
-			},
-		},
-		"sort": []interface{}{
-			map[string]interface{}{
-				"publication_date": map[string]interface{}{
-					"order": "desc",
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					countryFilter(strings.Trim(request.Country, " ")),
+					regionFilter(strings.Trim(request.Region, " ")),
 				},
 			},
 		},
+		"sort": []interface{}{publicationDateSort()},
+	}
+
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
+		return
 	}
 
-	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
+	err := n.mapResponse(ctx, elasticResponse, request, &response)
 	if err != nil {
-		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
+		return
+	}
+
+	return
+}
+
+func (n *NewsService) GetNewsByLocation(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if werr = n.resolveLocation(ctx, &request); werr != nil {
+		return
+	}
+	if !hasLocation(request) {
+		logrus.WithContext(ctx.Ctx).Error("location is required")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("location is required"))
+		return
+	}
+	distance, err := radiusDistance(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+
+	query := map[string]interface{}{
+		"query": geoDistanceFilter(*request.Lat, *request.Long, distance),
+		"sort":  []interface{}{geoDistanceSort(*request.Lat, *request.Long)},
+	}
+
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
 		return
 	}
 
@@ -136,53 +266,41 @@ func (n *NewsService) GetNewsBySource(ctx *utils.Context, request vm.FetchNewsRe
 		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
 		return
 	}
+
 	return
 }
 
-func (n *NewsService) GetNewsByLocation(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
-	if request.Lat <= 0 {
-		logrus.WithContext(ctx.Ctx).Error("invalid latitute")
-		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid latitute"))
+// GetNewsWithinArea returns articles inside a bounding box or a GeoJSON
+// polygon, for map views.
+func (n *NewsService) GetNewsWithinArea(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if werr = n.resolveLocation(ctx, &request); werr != nil {
 		return
 	}
-	if request.Long <= 0 {
-		logrus.WithContext(ctx.Ctx).Error("invalid longitude")
-		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid longitude"))
+
+	area, err := areaFilter(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
 		return
 	}
-	if request.Radius <= 0 {
-		request.Radius = 10
+	filters, err := n.buildFilters(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
 	}
 
 	query := map[string]interface{}{
 		"query": map[string]interface{}{
-			"geo_distance": map[string]interface{}{
-				"distance": fmt.Sprintf("%vkm", request.Radius),
-				"location": map[string]float64{
-					"lat": request.Lat,
-					"lon": request.Long,
-				},
-			},
-		},
-		"sort": []interface{}{
-			map[string]interface{}{
-				"_geo_distance": map[string]interface{}{
-					"location": map[string]float64{
-						"lat": request.Lat,
-						"lon": request.Long,
-					},
-					"order":           "asc",
-					"unit":            "km",
-					"distance_type":   "arc",
-					"ignore_unmapped": true,
-				},
+			"bool": map[string]interface{}{
+				"filter": append(filters, area),
 			},
 		},
+		"sort": []interface{}{publicationDateSort()},
 	}
 
-	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
-	if err != nil {
-		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
 		return
 	}
 
@@ -201,60 +319,104 @@ func (n *NewsService) GetNewsBySearch(ctx *utils.Context, request vm.FetchNewsRe
 		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid query"))
 		return
 	}
+	switch request.Mode {
+	case DEFAULT_SEARCH_MODE:
+	case LEXICAL_SEARCH_MODE:
+	case ADVANCED_SEARCH_MODE:
+		return n.getNewsByAdvancedSearch(ctx, request)
+	case HYBRID_SEARCH_MODE:
+		return n.getNewsByHybridSearch(ctx, request)
+	default:
+		logrus.WithContext(ctx.Ctx).Error("invalid mode")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid mode"))
+		return
+	}
 
-	llmOutput, err := n.llmService.AnalyzeQuery(ctx, request.Query)
-	if err != nil {
+	// Without the LLM the search still runs on text alone; the default mode
+	// reports that as degraded since the intents could not be applied.
+	var llmOutput *llm_service.LlmOutput
+	degraded := false
+	if request.Mode != LEXICAL_SEARCH_MODE {
+		var err error
+		llmOutput, err = n.llmService.AnalyzeQuery(ctx, request.Query)
+		if err != nil {
+			logrus.WithContext(ctx.Ctx).Errorf("query analysis failed, falling back to lexical search: %v", err)
+			degraded = true
+		}
+	}
+
+	if werr = n.resolveLocation(ctx, &request); werr != nil {
 		return
 	}
+	nearby := n.nearbyLocation(request, llmOutput)
+	recency := request.Recency || hasIntent(llmOutput, LATEST_INTENT)
 
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"function_score": map[string]interface{}{
-				"query": map[string]interface{}{
-					"bool": map[string]interface{}{
-						"should": append(
-							[]interface{}{
-								map[string]interface{}{
-									"multi_match": map[string]interface{}{
-										"query": request.Query,
-										"fields": []string{
-											"title^3",
-											"description",
-										},
-										"type":      "best_fields",
-										"fuzziness": "AUTO",
-									},
-								},
-							},
-							subQueriesBasedOnIntent(llmOutput)...,
-						),
-						"minimum_should_match": 1,
-					},
-				},
+	query := n.searchQuery(request.Query, llmOutput, nearby)
+	if recency {
+		withRecencyDecay(query)
+	}
+	n.withCredibility(query)
+	query["suggest"] = didYouMeanSuggestion(request.Query)
 
-				"boost_mode": "sum",
-				"score_mode": "sum",
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
+		return
+	}
 
-				"functions": []interface{}{
-					map[string]interface{}{
-						"field_value_factor": map[string]interface{}{
-							"field":    "relevance_score",
-							"factor":   5,
-							"modifier": "sqrt",
-							"missing":  0,
-						},
-					},
-				},
-			},
-		},
+	didYouMean := ""
+	if totalHits(elasticResponse) < DID_YOU_MEAN_THRESHOLD {
+		didYouMean = parseDidYouMean(elasticResponse, request.Query)
+	}
+	if didYouMean != "" && request.AutoCorrect && !request.IsCursorMode() {
+		corrected := request
+		corrected.Query = didYouMean
+		correctedQuery := n.searchQuery(didYouMean, llmOutput, nearby)
+		if recency {
+			withRecencyDecay(correctedQuery)
+		}
+		n.withCredibility(correctedQuery)
+		elasticResponse, werr = n.searchNews(ctx, correctedQuery, corrected)
+		if werr != nil {
+			return
+		}
 	}
 
-	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
+	err := n.mapResponse(ctx, elasticResponse, request, &response)
 	if err != nil {
 		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
 		return
 	}
 
+	response.MetaResponse.Degraded = degraded
+	if request.AutoCorrect && !request.IsCursorMode() {
+		response.MetaResponse.CorrectedQuery = didYouMean
+	} else {
+		response.MetaResponse.DidYouMean = didYouMean
+	}
+	return
+}
+
+// getNewsByAdvancedSearch runs the query language of query_parser.go without
+// the LLM or spelling suggestions, as the query is taken literally.
+func (n *NewsService) getNewsByAdvancedSearch(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	clause, err := parseAdvancedQuery(request.Query)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, fmt.Errorf("invalid query: %v", err))
+		return
+	}
+
+	query := advancedSearchQuery(clause)
+	if request.Recency {
+		withRecencyDecay(query)
+	}
+	n.withCredibility(query)
+
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
+		return
+	}
+
 	err = n.mapResponse(ctx, elasticResponse, request, &response)
 	if err != nil {
 		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
@@ -265,60 +427,164 @@ func (n *NewsService) GetNewsBySearch(ctx *utils.Context, request vm.FetchNewsRe
 }
 
 func (n *NewsService) GetTrendingNewsByLocation(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
-	if request.Lat <= 0 {
-		logrus.WithContext(ctx.Ctx).Error("invalid latitute")
-		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid latitute"))
+	if werr = n.resolveLocation(ctx, &request); werr != nil {
+		return
+	}
+	if !hasLocation(request) {
+		logrus.WithContext(ctx.Ctx).Error("location is required")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("location is required"))
+		return
+	}
+
+	config, err := n.trendingConfig(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+
+	query := trendingQuery(config, &vm.LatLon{Lat: *request.Lat, Lon: *request.Long}, nil)
+
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
+		return
+	}
+
+	err = n.mapResponse(ctx, elasticResponse, request, &response)
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
+		return
+	}
+
+	return
+}
+
+// GetNewsByID returns a single article from MySQL, falling back to the
+// search index when it is missing there. Trending stats only live in the
+// index and are merged in when available.
+func (n *NewsService) GetNewsByID(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.News, werr utils.WrapperError) {
+	if request.ID == 0 {
+		logrus.WithContext(ctx.Ctx).Error("invalid id")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	dbNews := models.News{}
+	dbErr := n.db.WithContext(ctx.Ctx).First(&dbNews, request.ID).Error
+	if dbErr != nil && !errors.Is(dbErr, gorm.ErrRecordNotFound) {
+		logrus.WithContext(ctx.Ctx).Error(dbErr)
+	}
+	if dbErr == nil && n.isFromDisabledSource(dbNews) {
+		werr = utils.NewWrapperError(http.StatusNotFound, errors.New("news not found"))
+		return
+	}
+
+	hit, err := n.getElasticHitByID(ctx, request.ID)
+	if err != nil && dbErr != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+
+	switch {
+	case dbErr == nil:
+		response = vm.News{
+			ID:              dbNews.ID,
+			Title:           dbNews.Title,
+			Description:     dbNews.Description,
+			Url:             dbNews.Url,
+			PublicationDate: dbNews.PublicationDate,
+			SourceName:      dbNews.SourceName,
+			Category:        strings.Split(dbNews.Category, ","),
+			RelevanceScore:  dbNews.RelevanceScore,
+			Latitude:        dbNews.Latitude,
+			Longitude:       dbNews.Longitude,
+			Language:        dbNews.Language,
+		}
+		if hit != nil {
+			newsElastic := toNewsElastic(hit)
+			response.Address = newsElastic.Address
+			response.RecentActivityScore = newsElastic.RecentActivityScore
+			response.LastEventTime = newsElastic.LastEventTime
+		}
+	case hit != nil:
+		response = toNews(toNewsElastic(hit))
+	default:
+		werr = utils.NewWrapperError(http.StatusNotFound, errors.New("news not found"))
 		return
 	}
-	if request.Long <= 0 {
-		logrus.WithContext(ctx.Ctx).Error("invalid longitude")
-		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid longitude"))
+
+	llmSummary, err := n.llmService.GenerateSummary(ctx, []string{response.Description})
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+	}
+	if len(llmSummary) == 1 {
+		response.LLMSummary = llmSummary[0]
+	}
+
+	return
+}
+
+// GetRelatedNews finds articles similar to the given one by title,
+// description, category and source. A radius restricts them to the region
+// around the article.
+func (n *NewsService) GetRelatedNews(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if request.ID == 0 {
+		logrus.WithContext(ctx.Ctx).Error("invalid id")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid id"))
 		return
 	}
 
+	hit, err := n.getElasticHitByID(ctx, request.ID)
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	if hit == nil {
+		werr = utils.NewWrapperError(http.StatusNotFound, errors.New("news not found"))
+		return
+	}
+
+	filters := make([]interface{}, 0)
+	if request.Radius > 0 {
+		distance, err := radiusDistance(request)
+		if err != nil {
+			logrus.WithContext(ctx.Ctx).Error(err)
+			werr = utils.NewWrapperError(http.StatusBadRequest, err)
+			return
+		}
+		newsElastic := toNewsElastic(hit)
+		filters = append(filters, geoDistanceFilter(newsElastic.Location.Lat, newsElastic.Location.Lon, distance))
+	}
+
 	query := map[string]interface{}{
 		"query": map[string]interface{}{
-			"function_score": map[string]interface{}{
-				"boost_mode": "sum",
-				"score_mode": "sum",
-				"functions": []interface{}{
-					map[string]interface{}{
-						"field_value_factor": map[string]interface{}{
-							"field":   "recent_activity_score",
-							"factor":  1.0,
-							"missing": 0,
-						},
-					},
-					map[string]interface{}{
-						"gauss": map[string]interface{}{
-							"location": map[string]interface{}{
-								"origin": map[string]float64{
-									"lat": request.Lat,
-									"lon": request.Long,
-								},
-								"scale":  "50km",
-								"offset": "0km",
-								"decay":  0.5,
-							},
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"more_like_this": map[string]interface{}{
+						"fields": []string{
+							"title",
+							"description",
+							"category",
+							"source_name",
 						},
-					},
-					map[string]interface{}{
-						"gauss": map[string]interface{}{
-							"last_event_time": map[string]interface{}{
-								"origin": time.Now().Format(time.RFC3339),
-								"scale":  "24h",
-								"decay":  0.5,
+						"like": []interface{}{
+							map[string]interface{}{
+								"_index": utils.NEWS_INDEX,
+								"_id":    hit["_id"],
 							},
 						},
+						"min_term_freq":   1,
+						"min_doc_freq":    1,
+						"max_query_terms": 25,
 					},
 				},
+				"filter": filters,
 			},
 		},
 	}
 
-	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
-	if err != nil {
-		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
 		return
 	}
 
@@ -331,6 +597,120 @@ func (n *NewsService) GetTrendingNewsByLocation(ctx *utils.Context, request vm.F
 	return
 }
 
+// getElasticHitByID returns the search hit of the article with the given
+// database ID, or nil if it is not indexed.
+func (n *NewsService) getElasticHitByID(ctx *utils.Context, id uint64) (hit map[string]interface{}, err error) {
+	filters := []interface{}{
+		map[string]interface{}{
+			"term": map[string]interface{}{
+				"id": id,
+			},
+		},
+	}
+	if sourceFilter := n.disabledSourceFilter(); sourceFilter != nil {
+		filters = append(filters, sourceFilter)
+	}
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+		"_source": sourceExcludes(),
+	}
+
+	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, vm.NewPaginationRequest(1, 1))
+	if err != nil {
+		return
+	}
+	hits, ok := elasticResponse["hits"].(map[string]interface{})
+	if !ok {
+		logrus.WithContext(ctx.Ctx).Errorf("error from elastic: %v", elasticResponse)
+		err = errors.New("something went wrong")
+		return
+	}
+	hitList, _ := hits["hits"].([]interface{})
+	if len(hitList) == 0 {
+		return
+	}
+	hit, _ = hitList[0].(map[string]interface{})
+	return
+}
+
+func toNews(newsElastic vm.NewsElastic) vm.News {
+	return vm.News{
+		ID:                  newsElastic.ID,
+		Title:               newsElastic.Title,
+		Description:         newsElastic.Description,
+		Url:                 newsElastic.Url,
+		PublicationDate:     newsElastic.PublicationDate,
+		SourceName:          newsElastic.SourceName,
+		Category:            newsElastic.Category,
+		RelevanceScore:      newsElastic.RelevanceScore,
+		Latitude:            newsElastic.Location.Lat,
+		Longitude:           newsElastic.Location.Lon,
+		Language:            newsElastic.Language,
+		Address:             newsElastic.Address,
+		RecentActivityScore: newsElastic.RecentActivityScore,
+		LastEventTime:       newsElastic.LastEventTime,
+	}
+}
+
+func toNewsElastic(hit map[string]interface{}) vm.NewsElastic {
+	bytes, _ := json.Marshal(hit["_source"])
+	newsElastic := vm.NewsElastic{}
+	json.Unmarshal(bytes, &newsElastic)
+	return newsElastic
+}
+
+// sourceExcludes leaves the embedding out of hits as no response uses it.
+func sourceExcludes() map[string]interface{} {
+	return map[string]interface{}{
+		"excludes": []string{"embedding"},
+	}
+}
+
+// searchNews adds the request-wide options shared by every list endpoint to
+// query before running it against the news index.
+func (n *NewsService) searchNews(ctx *utils.Context, query map[string]interface{}, request vm.FetchNewsRequest) (elasticResponse map[string]interface{}, werr utils.WrapperError) {
+	query["_source"] = sourceExcludes()
+	if request.Facets {
+		query["aggs"] = facetAggregations()
+	}
+	if request.Highlight {
+		query["highlight"] = highlightQuery()
+	}
+
+	if request.Sort != "" {
+		sort, err := buildSort(request)
+		if err != nil {
+			logrus.WithContext(ctx.Ctx).Error(err)
+			werr = utils.NewWrapperError(http.StatusBadRequest, err)
+			return
+		}
+		query["sort"] = sort
+	}
+
+	filters, err := n.requestFilters(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+	withFilters(query, filters)
+
+	elasticResponse, err = n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, request.PaginationRequest)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	return
+}
+
 func (n *NewsService) mapResponse(ctx *utils.Context, elasticResponse map[string]interface{}, request vm.FetchNewsRequest, response *vm.NewsResponse) (err error) {
 	hits, ok := elasticResponse["hits"].(map[string]interface{})
 	if !ok {
@@ -341,34 +721,29 @@ func (n *NewsService) mapResponse(ctx *utils.Context, elasticResponse map[string
 	totalHits := int64(hits["total"].(map[string]interface{})["value"].(float64))
 	data := make([]vm.News, 0)
 	descriptions := make([]string, 0)
-	for _, hit := range elasticResponse["hits"].(map[string]interface{})["hits"].([]interface{}) {
-		source := hit.(map[string]interface{})["_source"]
-		bytes, _ := json.Marshal(source)
-		newsElastic := vm.NewsElastic{}
-		json.Unmarshal(bytes, &newsElastic)
-		data = append(data, vm.News{
-			Title:           newsElastic.Title,
-			Description:     newsElastic.Description,
-			Url:             newsElastic.Url,
-			PublicationDate: newsElastic.PublicationDate,
-			SourceName:      newsElastic.SourceName,
-			Category:        newsElastic.Category,
-			RelevanceScore:  newsElastic.RelevanceScore,
-			Latitude:        newsElastic.Location.Lat,
-			Longitude:       newsElastic.Location.Lon,
-		})
+	for _, h := range elasticResponse["hits"].(map[string]interface{})["hits"].([]interface{}) {
+		hit := h.(map[string]interface{})
+		newsElastic := toNewsElastic(hit)
+		news := toNews(newsElastic)
+		news.Highlights = mapHighlights(hit)
+		if hasLocation(request) {
+			distance := geo_service.DistanceKm(*request.Lat, *request.Long, news.Latitude, news.Longitude)
+			news.DistanceKm = &distance
+		}
+		data = append(data, news)
 		descriptions = append(descriptions, newsElastic.Description)
 	}
 
-	llmSummary, err := n.llmService.GenerateSummary(ctx, descriptions)
-	if err != nil {
-		logrus.WithContext(ctx.Ctx).Error(err)
-		err = nil
-	}
+	if request.Mode != LEXICAL_SEARCH_MODE {
+		llmSummary, err := n.llmService.GenerateSummary(ctx, descriptions)
+		if err != nil {
+			logrus.WithContext(ctx.Ctx).Error(err)
+		}
 
-	if len(data) == len(llmSummary) {
-		for i := 0; i < len(data); i++ {
-			data[i].LLMSummary = llmSummary[i]
+		if len(data) == len(llmSummary) {
+			for i := 0; i < len(data); i++ {
+				data[i].LLMSummary = llmSummary[i]
+			}
 		}
 	}
 
@@ -384,20 +759,74 @@ func (n *NewsService) mapResponse(ctx *utils.Context, elasticResponse map[string
 	var searchQuery map[string]interface{}
 	json.Unmarshal(requestInBytes, &searchQuery)
 	response.MetaResponse.SearchQuery = searchQuery
+	if request.IsCursorMode() {
+		response.MetaResponse.NextCursor = utils.NextCursor(elasticResponse, request.GetLimit())
+	}
+	if aggregations, ok := elasticResponse["aggregations"].(map[string]interface{}); ok {
+		response.Facets = mapFacets(aggregations)
+	}
 	return
 }
 
-func subQueriesBasedOnIntent(llmOutput *llm_service.LlmOutput) []interface{} {
+func (n *NewsService) searchQuery(text string, llmOutput *llm_service.LlmOutput, nearby *vm.LatLon) map[string]interface{} {
+	functions := []interface{}{
+		map[string]interface{}{
+			"field_value_factor": map[string]interface{}{
+				"field":    "relevance_score",
+				"factor":   5,
+				"modifier": "sqrt",
+				"missing":  0,
+			},
+		},
+	}
+	if nearby != nil {
+		functions = append(functions, nearbyFunction(*nearby))
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"function_score": map[string]interface{}{
+				"query": map[string]interface{}{
+					"bool": map[string]interface{}{
+						"should": append(
+							[]interface{}{
+								map[string]interface{}{
+									"multi_match": map[string]interface{}{
+										"query":     text,
+										"fields":    searchFields(),
+										"type":      "best_fields",
+										"fuzziness": "AUTO",
+									},
+								},
+							},
+							n.subQueriesBasedOnIntent(llmOutput)...,
+						),
+						"minimum_should_match": 1,
+					},
+				},
+
+				"boost_mode": "sum",
+				"score_mode": "sum",
+
+				"functions": functions,
+			},
+		},
+	}
+}
+
+func (n *NewsService) subQueriesBasedOnIntent(llmOutput *llm_service.LlmOutput) []interface{} {
 	subQuery := make([]interface{}, 0)
+	if llmOutput == nil {
+		return subQuery
+	}
+	subQuery = append(subQuery, entityPhraseQueries(llmOutput.Entities)...)
 	for _, i := range llmOutput.Intent {
 		switch i {
-		case "category":
-			subQuery = append(subQuery, map[string]interface{}{
-				"terms": map[string]interface{}{
-					"category": llmOutput.Entities,
-				},
-			})
-		case "source":
+		case CATEGORY_INTENT:
+			for _, entity := range llmOutput.Entities {
+				subQuery = append(subQuery, n.categoryTreeFilter(entity))
+			}
+		case SOURCE_INTENT:
 			subQuery = append(subQuery, map[string]interface{}{
 				"terms": map[string]interface{}{
 					"source_name.keyword": llmOutput.Entities,
@@ -0,0 +1,406 @@
+package news_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The advanced search language supports:
+//   - bare words and "quoted phrases", matched against title and description
+//   - AND, OR and NOT (or a leading -), with AND being implied between terms
+//   - parentheses for grouping
+//   - field prefixes title:, source: and category:, e.g. source:"ANI News"
+//   - date ranges such as date:2025-01-01..2025-01-31, date:>=2025-01-01 or
+//     date:2025-01-15 for a single day
+//
+// It is compiled straight into Elasticsearch bool queries so no raw
+// query_string syntax ever reaches the cluster.
+
+const (
+	MAX_ADVANCED_QUERY_LENGTH = 500
+	MAX_ADVANCED_QUERY_TERMS  = 50
+	MAX_ADVANCED_QUERY_DEPTH  = 10
+)
+
+const (
+	tokenWord = iota
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type queryToken struct {
+	kind  int
+	field string
+	value string
+}
+
+type queryParser struct {
+	tokens   []queryToken
+	position int
+	terms    int
+	depth    int
+	// negated is set while parsing a term that ends up under must_not.
+	negated bool
+}
+
+var queryFields = map[string]bool{
+	"title":    true,
+	"source":   true,
+	"category": true,
+	"date":     true,
+}
+
+// parseAdvancedQuery compiles text into an Elasticsearch query clause.
+func parseAdvancedQuery(text string) (clause map[string]interface{}, err error) {
+	if utf8.RuneCountInString(text) > MAX_ADVANCED_QUERY_LENGTH {
+		return nil, errors.New("query is too long")
+	}
+	tokens, err := tokenizeQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty query")
+	}
+
+	parser := &queryParser{tokens: tokens}
+	clause, err = parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.position < len(parser.tokens) {
+		return nil, errors.New("unexpected )")
+	}
+	return
+}
+
+func tokenizeQuery(text string) (tokens []queryToken, err error) {
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose})
+			i++
+		case r == '-':
+			tokens = append(tokens, queryToken{kind: tokenNot})
+			i++
+		case r == '"':
+			phrase, next, err := readPhrase(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokenPhrase, value: phrase})
+			i = next
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			word := string(runes[start:i])
+			field, value, hasField := strings.Cut(word, ":")
+			if hasField && queryFields[strings.ToLower(field)] {
+				field = strings.ToLower(field)
+				if value == "" && i < len(runes) && runes[i] == '"' {
+					phrase, next, err := readPhrase(runes, i)
+					if err != nil {
+						return nil, err
+					}
+					tokens = append(tokens, queryToken{kind: tokenPhrase, field: field, value: phrase})
+					i = next
+					continue
+				}
+				if value == "" {
+					return nil, fmt.Errorf("missing value for %v:", field)
+				}
+				tokens = append(tokens, queryToken{kind: tokenWord, field: field, value: value})
+				continue
+			}
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{kind: tokenAnd})
+			case "OR":
+				tokens = append(tokens, queryToken{kind: tokenOr})
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: tokenNot})
+			default:
+				tokens = append(tokens, queryToken{kind: tokenWord, value: word})
+			}
+		}
+	}
+	return
+}
+
+// readPhrase reads the quoted phrase starting at runes[start] and returns it
+// along with the position right after the closing quote.
+func readPhrase(runes []rune, start int) (phrase string, next int, err error) {
+	end := start + 1
+	for end < len(runes) && runes[end] != '"' {
+		end++
+	}
+	if end == len(runes) {
+		return "", 0, errors.New("unterminated phrase")
+	}
+	phrase = strings.Trim(string(runes[start+1:end]), " ")
+	if phrase == "" {
+		return "", 0, errors.New("empty phrase")
+	}
+	return phrase, end + 1, nil
+}
+
+func (p *queryParser) peek() (token queryToken, ok bool) {
+	if p.position >= len(p.tokens) {
+		return
+	}
+	return p.tokens[p.position], true
+}
+
+func (p *queryParser) parseOr() (map[string]interface{}, error) {
+	clauses := make([]interface{}, 0)
+	for {
+		clause, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+		if token, ok := p.peek(); !ok || token.kind != tokenOr {
+			break
+		}
+		p.position++
+	}
+	if len(clauses) == 1 {
+		return clauses[0].(map[string]interface{}), nil
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should":               clauses,
+			"minimum_should_match": 1,
+		},
+	}, nil
+}
+
+func (p *queryParser) parseAnd() (map[string]interface{}, error) {
+	must := make([]interface{}, 0)
+	mustNot := make([]interface{}, 0)
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind == tokenOr || token.kind == tokenClose {
+			break
+		}
+		if token.kind == tokenAnd {
+			p.position++
+			continue
+		}
+		negated := false
+		for ok && token.kind == tokenNot {
+			negated = !negated
+			p.position++
+			token, ok = p.peek()
+		}
+		if negated {
+			p.negated = !p.negated
+		}
+		clause, err := p.parsePrimary()
+		if negated {
+			p.negated = !p.negated
+		}
+		if err != nil {
+			return nil, err
+		}
+		if negated {
+			mustNot = append(mustNot, clause)
+		} else {
+			must = append(must, clause)
+		}
+	}
+	if len(must)+len(mustNot) == 0 {
+		return nil, errors.New("missing search term")
+	}
+	if len(must) == 1 && len(mustNot) == 0 {
+		return must[0].(map[string]interface{}), nil
+	}
+	boolQuery := map[string]interface{}{}
+	if len(must) > 0 {
+		boolQuery["must"] = must
+	}
+	if len(mustNot) > 0 {
+		boolQuery["must_not"] = mustNot
+	}
+	return map[string]interface{}{"bool": boolQuery}, nil
+}
+
+func (p *queryParser) parsePrimary() (map[string]interface{}, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, errors.New("missing search term")
+	}
+	p.position++
+
+	switch token.kind {
+	case tokenOpen:
+		p.depth++
+		if p.depth > MAX_ADVANCED_QUERY_DEPTH {
+			return nil, errors.New("query is nested too deeply")
+		}
+		clause, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token, ok := p.peek(); !ok || token.kind != tokenClose {
+			return nil, errors.New("missing )")
+		}
+		p.position++
+		p.depth--
+		return clause, nil
+	case tokenWord, tokenPhrase:
+		p.terms++
+		if p.terms > MAX_ADVANCED_QUERY_TERMS {
+			return nil, errors.New("query has too many terms")
+		}
+		return compileTerm(token, !p.negated)
+	}
+	return nil, errors.New("unexpected operator")
+}
+
+// compileTerm builds the clause for a single term. Fuzzy matching is only used
+// for terms that must match: on an excluded term it would also drop articles
+// with similar words, e.g. -india would exclude "indian".
+func compileTerm(token queryToken, fuzzy bool) (map[string]interface{}, error) {
+	phrase := token.kind == tokenPhrase
+	switch token.field {
+	case "title":
+		if phrase {
+			return map[string]interface{}{"match_phrase": map[string]interface{}{"title": token.value}}, nil
+		}
+		return map[string]interface{}{"match": map[string]interface{}{"title": token.value}}, nil
+	case "source":
+		return sourceFilter(token.value), nil
+	case "category":
+		return map[string]interface{}{"match": map[string]interface{}{"category": token.value}}, nil
+	case "date":
+		return compileDateRange(token.value)
+	}
+
+	if phrase {
+		return map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  token.value,
+				"fields": searchFields(),
+				"type":   "phrase",
+			},
+		}, nil
+	}
+	multiMatch := map[string]interface{}{
+		"query":  token.value,
+		"fields": searchFields(),
+	}
+	if fuzzy {
+		multiMatch["fuzziness"] = "AUTO"
+	}
+	return map[string]interface{}{"multi_match": multiMatch}, nil
+}
+
+// compileDateRange accepts from..to (either side may be empty), >, >=, <, <=
+// or a single date.
+func compileDateRange(value string) (map[string]interface{}, error) {
+	dateRange := map[string]interface{}{}
+	var err error
+	switch {
+	case strings.Contains(value, ".."):
+		from, to, _ := strings.Cut(value, "..")
+		if from == "" && to == "" {
+			return nil, errors.New("invalid date range")
+		}
+		if from != "" {
+			err = addDateBound(dateRange, from, true, true)
+		}
+		if to != "" && err == nil {
+			err = addDateBound(dateRange, to, false, true)
+		}
+	case strings.HasPrefix(value, ">="):
+		err = addDateBound(dateRange, value[2:], true, true)
+	case strings.HasPrefix(value, "<="):
+		err = addDateBound(dateRange, value[2:], false, true)
+	case strings.HasPrefix(value, ">"):
+		err = addDateBound(dateRange, value[1:], true, false)
+	case strings.HasPrefix(value, "<"):
+		err = addDateBound(dateRange, value[1:], false, false)
+	default:
+		if err = addDateBound(dateRange, value, true, true); err == nil {
+			err = addDateBound(dateRange, value, false, true)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"range": map[string]interface{}{
+			"publication_date": dateRange,
+		},
+	}, nil
+}
+
+// addDateBound sets the lower or upper bound of dateRange. A full timestamp is
+// used as is. A plain date stands for the whole day, so an inclusive upper or
+// exclusive lower bound moves to the start of the following day.
+func addDateBound(dateRange map[string]interface{}, value string, lower bool, inclusive bool) error {
+	t, dateOnly, err := parseDate(value)
+	if err != nil {
+		return fmt.Errorf("invalid date %v", value)
+	}
+	operator := "lt"
+	if lower {
+		operator = "gt"
+	}
+	if inclusive {
+		operator += "e"
+	}
+	if dateOnly {
+		if lower != inclusive {
+			t = t.AddDate(0, 0, 1)
+		}
+		operator = "lt"
+		if lower {
+			operator = "gte"
+		}
+	}
+	dateRange[operator] = t.Format(time.RFC3339)
+	return nil
+}
+
+// advancedSearchQuery wraps a compiled clause with the same relevance_score
+// boost the default search applies.
+func advancedSearchQuery(clause map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"function_score": map[string]interface{}{
+				"query":      clause,
+				"boost_mode": "sum",
+				"score_mode": "sum",
+				"functions": []interface{}{
+					map[string]interface{}{
+						"field_value_factor": map[string]interface{}{
+							"field":    "relevance_score",
+							"factor":   5,
+							"modifier": "sqrt",
+							"missing":  0,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package news_service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseAdvancedQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		contains []string
+		excludes []string
+		err      string
+	}{
+		{
+			name:     "phrase",
+			query:    `"climate summit"`,
+			contains: []string{`"query":"climate summit"`, `"type":"phrase"`},
+			excludes: []string{"fuzziness"},
+		},
+		{
+			name:     "bare word is fuzzy",
+			query:    "cricket",
+			contains: []string{`"fuzziness":"AUTO"`},
+		},
+		{
+			name:  "minus excludes without fuzziness",
+			query: "election -india",
+			// Keys are marshalled in order, so fuzziness would sit between
+			// fields and query.
+			contains: []string{`"fuzziness":"AUTO","query":"election"`, `],"query":"india"}}]`},
+		},
+		{
+			name:     "NOT group excludes without fuzziness",
+			query:    "election NOT (india OR pakistan)",
+			contains: []string{`"must_not":[{"bool":{"minimum_should_match":1`, `],"query":"pakistan"}}`},
+		},
+		{
+			name:     "double negation keeps fuzziness",
+			query:    "NOT -cricket",
+			contains: []string{`"fuzziness":"AUTO"`},
+			excludes: []string{"must_not"},
+		},
+		{
+			name:     "OR binds looser than AND",
+			query:    "a b OR c",
+			contains: []string{`{"bool":{"minimum_should_match":1,"should":[{"bool":{"must":[`},
+		},
+		{
+			name:     "field prefixes",
+			query:    `title:"budget session" category:sports`,
+			contains: []string{`"match_phrase":{"title":"budget session"}`, `"match":{"category":"sports"}`},
+		},
+		{
+			name:     "single day",
+			query:    "date:2025-01-15",
+			contains: []string{`"gte":"2025-01-15T00:00:00Z"`, `"lt":"2025-01-16T00:00:00Z"`},
+		},
+		{
+			name:     "date range includes the last day",
+			query:    "date:2025-01-01..2025-01-31",
+			contains: []string{`"gte":"2025-01-01T00:00:00Z"`, `"lt":"2025-02-01T00:00:00Z"`},
+		},
+		{
+			name:     "open date range",
+			query:    "date:..2025-01-31",
+			contains: []string{`"lt":"2025-02-01T00:00:00Z"`},
+			excludes: []string{"gte"},
+		},
+		{
+			name:     "after a day",
+			query:    "date:>2025-01-15",
+			contains: []string{`"gte":"2025-01-16T00:00:00Z"`},
+		},
+		{
+			name:     "before a day",
+			query:    "date:<2025-01-15",
+			contains: []string{`"lt":"2025-01-15T00:00:00Z"`},
+		},
+		{
+			name:     "up to a timestamp",
+			query:    "date:<=2025-01-31T10:00:00Z",
+			contains: []string{`"lte":"2025-01-31T10:00:00Z"`},
+		},
+		{
+			name:     "after a timestamp",
+			query:    "date:>2025-01-31T10:00:00Z",
+			contains: []string{`"gt":"2025-01-31T10:00:00Z"`},
+		},
+		{name: "empty", query: "  ", err: "empty query"},
+		{name: "missing close", query: "(a OR b", err: "missing )"},
+		{name: "extra close", query: "a OR b)", err: "unexpected )"},
+		{name: "unterminated phrase", query: `"a b`, err: "unterminated phrase"},
+		{name: "dangling operator", query: "a OR", err: "missing search term"},
+		{name: "invalid date", query: "date:yesterday", err: "invalid date"},
+		{name: "missing field value", query: "source:", err: "missing value"},
+		{name: "too deep", query: strings.Repeat("(", MAX_ADVANCED_QUERY_DEPTH+1) + "a" + strings.Repeat(")", MAX_ADVANCED_QUERY_DEPTH+1), err: "nested too deeply"},
+		{name: "too many terms", query: strings.Repeat("a ", MAX_ADVANCED_QUERY_TERMS+1), err: "too many terms"},
+		{name: "too long", query: strings.Repeat("a", MAX_ADVANCED_QUERY_LENGTH+1), err: "too long"},
+		{
+			name:     "length is counted in characters",
+			query:    strings.Repeat("चुनाव ", MAX_ADVANCED_QUERY_LENGTH/12),
+			contains: []string{`"query":"चुनाव"`},
+		},
+		{name: "too many characters", query: strings.Repeat("च", MAX_ADVANCED_QUERY_LENGTH+1), err: "too long"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clause, err := parseAdvancedQuery(test.query)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := json.Marshal(clause)
+			for _, want := range test.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("%s\ndoes not contain %s", data, want)
+				}
+			}
+			for _, unwanted := range test.excludes {
+				if strings.Contains(string(data), unwanted) {
+					t.Errorf("%s\ncontains %s", data, unwanted)
+				}
+			}
+		})
+	}
+}
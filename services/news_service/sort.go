@@ -0,0 +1,85 @@
+package news_service
+
+import (
+	"errors"
+	"strings"
+
+	"news_service/models/vm"
+)
+
+const (
+	SORT_ASC  = "asc"
+	SORT_DESC = "desc"
+
+	SORT_BY_DATE      = "date"
+	SORT_BY_RELEVANCE = "relevance"
+	SORT_BY_DISTANCE  = "distance"
+	SORT_BY_TRENDING  = "trending"
+	SORT_BY_SCORE     = "score"
+)
+
+// defaultSortOrder lists the sort keys clients may use along with their
+// default direction.
+var defaultSortOrder = map[string]string{
+	SORT_BY_DATE:      SORT_DESC,
+	SORT_BY_RELEVANCE: SORT_DESC,
+	SORT_BY_DISTANCE:  SORT_ASC,
+	SORT_BY_TRENDING:  SORT_DESC,
+	SORT_BY_SCORE:     SORT_DESC,
+}
+
+// buildSort parses a comma separated list of key[:direction] pairs, e.g.
+// "trending,date:asc", into an Elasticsearch sort.
+func buildSort(request vm.FetchNewsRequest) (sort []interface{}, err error) {
+	sort = make([]interface{}, 0)
+	for _, part := range strings.Split(request.Sort, ",") {
+		key, order, _ := strings.Cut(strings.Trim(part, " "), ":")
+		key = strings.ToLower(key)
+		order = strings.ToLower(order)
+		defaultOrder, ok := defaultSortOrder[key]
+		if !ok {
+			return nil, errors.New("invalid sort")
+		}
+		if order == "" {
+			order = defaultOrder
+		}
+		if order != SORT_ASC && order != SORT_DESC {
+			return nil, errors.New("invalid sort order")
+		}
+
+		switch key {
+		case SORT_BY_DATE:
+			sort = append(sort, fieldSort("publication_date", order))
+		case SORT_BY_RELEVANCE:
+			sort = append(sort, fieldSort("relevance_score", order))
+		case SORT_BY_TRENDING:
+			trendingSort := fieldSort("recent_activity_score", order)
+			// recent_activity_score only exists once processAndFlush has scored an article.
+			trendingSort["recent_activity_score"].(map[string]interface{})["unmapped_type"] = "float"
+			sort = append(sort, trendingSort)
+		case SORT_BY_SCORE:
+			sort = append(sort, map[string]interface{}{
+				"_score": map[string]interface{}{
+					"order": order,
+				},
+			})
+		case SORT_BY_DISTANCE:
+			if !hasLocation(request) {
+				return nil, errors.New("lat and long are required to sort by distance")
+			}
+			distanceSort := geoDistanceSort(*request.Lat, *request.Long)
+			distanceSort["_geo_distance"].(map[string]interface{})["order"] = order
+			sort = append(sort, distanceSort)
+		}
+	}
+	return
+}
+
+func fieldSort(field string, order string) map[string]interface{} {
+	return map[string]interface{}{
+		field: map[string]interface{}{
+			"order":   order,
+			"missing": "_last",
+		},
+	}
+}
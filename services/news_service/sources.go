@@ -0,0 +1,67 @@
+package news_service
+
+import (
+	"news_service/models"
+)
+
+// isFromDisabledSource reports whether an article read from MySQL belongs to
+// a disabled source.
+func (n *NewsService) isFromDisabledSource(news models.News) bool {
+	sourceID := uint64(0)
+	if news.SourceID != nil {
+		sourceID = *news.SourceID
+	}
+	return n.sourceService.IsDisabled(sourceID, news.SourceName)
+}
+
+// disabledSourceFilter excludes articles of disabled sources, matching on the
+// source name too for articles indexed before they had a source ID.
+func (n *NewsService) disabledSourceFilter() map[string]interface{} {
+	ids, names := n.sourceService.Disabled()
+	if len(ids) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must_not": []interface{}{
+				map[string]interface{}{
+					"terms": map[string]interface{}{"source_id": ids},
+				},
+				map[string]interface{}{
+					"terms": map[string]interface{}{"source_name.keyword": names},
+				},
+			},
+		},
+	}
+}
+
+// withCredibility multiplies the score of each article by the credibility of
+// its source. Sources with the default credibility are left as they are.
+func (n *NewsService) withCredibility(query map[string]interface{}) {
+	groups := n.sourceService.CredibilityGroups()
+	if len(groups) == 0 {
+		return
+	}
+	functions := make([]interface{}, 0, len(groups))
+	for credibility, ids := range groups {
+		functions = append(functions, map[string]interface{}{
+			"filter": map[string]interface{}{
+				"terms": map[string]interface{}{"source_id": ids},
+			},
+			"weight": credibility,
+		})
+	}
+
+	inner, ok := query["query"]
+	if !ok {
+		inner = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	query["query"] = map[string]interface{}{
+		"function_score": map[string]interface{}{
+			"query":      inner,
+			"functions":  functions,
+			"score_mode": "first",
+			"boost_mode": "multiply",
+		},
+	}
+}
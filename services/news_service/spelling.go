@@ -0,0 +1,59 @@
+package news_service
+
+import (
+	"strings"
+)
+
+const (
+	DID_YOU_MEAN            = "did_you_mean"
+	DID_YOU_MEAN_THRESHOLD  = 3
+	DID_YOU_MEAN_CONFIDENCE = 1.0
+)
+
+// didYouMeanSuggestion asks for a corrected version of the query built from
+// the terms present in article titles.
+func didYouMeanSuggestion(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"text": text,
+		DID_YOU_MEAN: map[string]interface{}{
+			"phrase": map[string]interface{}{
+				"field":      "title",
+				"size":       1,
+				"confidence": DID_YOU_MEAN_CONFIDENCE,
+				"direct_generator": []interface{}{
+					map[string]interface{}{
+						"field":        "title",
+						"suggest_mode": "always",
+					},
+				},
+			},
+		},
+	}
+}
+
+// parseDidYouMean returns the best suggested correction, or an empty string
+// when there is none or it only differs from the query in case.
+func parseDidYouMean(elasticResponse map[string]interface{}, text string) string {
+	suggest, _ := elasticResponse["suggest"].(map[string]interface{})
+	entries, _ := suggest[DID_YOU_MEAN].([]interface{})
+	for _, e := range entries {
+		entry, _ := e.(map[string]interface{})
+		options, _ := entry["options"].([]interface{})
+		if len(options) == 0 {
+			continue
+		}
+		option, _ := options[0].(map[string]interface{})
+		corrected, _ := option["text"].(string)
+		if corrected != "" && !strings.EqualFold(corrected, strings.Trim(text, " ")) {
+			return corrected
+		}
+	}
+	return ""
+}
+
+func totalHits(elasticResponse map[string]interface{}) int64 {
+	hits, _ := elasticResponse["hits"].(map[string]interface{})
+	total, _ := hits["total"].(map[string]interface{})
+	value, _ := total["value"].(float64)
+	return int64(value)
+}
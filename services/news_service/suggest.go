@@ -0,0 +1,135 @@
+package news_service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"news_service/models/vm"
+	"news_service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SUGGEST_SIZE      = 5
+	SOURCE_SUGGEST    = "sources"
+	CATEGORY_SUGGEST  = "categories"
+	SUGGEST_BUCKETS   = "names"
+	SUGGEST_MAX_QUERY = 100
+)
+
+// GetSuggestions returns title, source and category completions for a
+// partially typed query. It is meant to be called on every keystroke, so it
+// never goes through the LLM.
+func (n *NewsService) GetSuggestions(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.SuggestResponse, werr utils.WrapperError) {
+	text := strings.Trim(request.Query, " ")
+	if text == "" || len(text) > SUGGEST_MAX_QUERY {
+		logrus.WithContext(ctx.Ctx).Error("invalid query")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid query"))
+		return
+	}
+
+	query := map[string]interface{}{
+		"_source":     []string{"title"},
+		"post_filter": suggestQuery(text, "title"),
+		"aggs": map[string]interface{}{
+			SOURCE_SUGGEST:   suggestAggregation(text, "source_name"),
+			CATEGORY_SUGGEST: suggestAggregation(text, "category"),
+		},
+	}
+	filters := make([]interface{}, 0)
+	if lang := strings.Trim(request.Lang, " "); lang != "" {
+		filters = append(filters, languageFilter(lang))
+	}
+	if sourceFilter := n.disabledSourceFilter(); sourceFilter != nil {
+		filters = append(filters, sourceFilter)
+	}
+	if len(filters) > 0 {
+		query["query"] = map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		}
+	}
+
+	elasticResponse, err := n.elastic.FetchFromElastic(ctx, query, utils.NEWS_INDEX, vm.NewPaginationRequest(1, SUGGEST_SIZE))
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	hits, ok := elasticResponse["hits"].(map[string]interface{})
+	if !ok {
+		logrus.WithContext(ctx.Ctx).Errorf("error from elastic: %v", elasticResponse)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+
+	response.Titles = make([]string, 0)
+	hitList, _ := hits["hits"].([]interface{})
+	for _, h := range hitList {
+		hit, _ := h.(map[string]interface{})
+		if title := toNewsElastic(hit).Title; title != "" {
+			response.Titles = append(response.Titles, title)
+		}
+	}
+
+	aggregations, _ := elasticResponse["aggregations"].(map[string]interface{})
+	response.Sources = suggestBuckets(aggregations[SOURCE_SUGGEST], text)
+	response.Categories = suggestBuckets(aggregations[CATEGORY_SUGGEST], text)
+	return
+}
+
+func suggestQuery(text string, field string) map[string]interface{} {
+	return map[string]interface{}{
+		"multi_match": map[string]interface{}{
+			"query": text,
+			"type":  "bool_prefix",
+			"fields": []string{
+				fmt.Sprintf("%v.suggest", field),
+				fmt.Sprintf("%v.suggest._2gram", field),
+				fmt.Sprintf("%v.suggest._3gram", field),
+			},
+		},
+	}
+}
+
+func suggestAggregation(text string, field string) map[string]interface{} {
+	return map[string]interface{}{
+		"filter": suggestQuery(text, field),
+		"aggs": map[string]interface{}{
+			SUGGEST_BUCKETS: map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": fmt.Sprintf("%v.keyword", field),
+					"size":  SUGGEST_SIZE * 4,
+				},
+			},
+		},
+	}
+}
+
+// suggestBuckets keeps only the values that actually start with the typed
+// text, since a matching document may carry other categories as well.
+func suggestBuckets(aggregation interface{}, text string) []string {
+	agg, _ := aggregation.(map[string]interface{})
+	suggestions := make([]string, 0)
+	for _, bucket := range mapFacetBuckets(agg[SUGGEST_BUCKETS]) {
+		if len(suggestions) == SUGGEST_SIZE {
+			break
+		}
+		if hasWordWithPrefix(bucket.Key, text) {
+			suggestions = append(suggestions, bucket.Key)
+		}
+	}
+	return suggestions
+}
+
+func hasWordWithPrefix(value string, prefix string) bool {
+	value = strings.ToLower(value)
+	prefix = strings.ToLower(prefix)
+	if strings.HasPrefix(value, prefix) {
+		return true
+	}
+	return strings.Contains(value, " "+prefix)
+}
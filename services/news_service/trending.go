@@ -0,0 +1,219 @@
+package news_service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"news_service/models/vm"
+	"news_service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	MIN_LOCALITY_SCALE_KM   = 1
+	MAX_LOCALITY_SCALE_KM   = 1000
+	MIN_RECENCY_SCALE_HOURS = 1
+	MAX_RECENCY_SCALE_HOURS = 720
+	MAX_ACTIVITY_FACTOR     = 100
+)
+
+// TrendingConfig holds the knobs of the trending ranking. Deployment-wide
+// defaults come from the environment and can be overridden per request.
+type TrendingConfig struct {
+	LocalityScaleKm   float64
+	RecencyScaleHours float64
+	Decay             float64
+	ActivityFactor    float64
+}
+
+func DefaultTrendingConfig() TrendingConfig {
+	return TrendingConfig{
+		LocalityScaleKm:   50,
+		RecencyScaleHours: 24,
+		Decay:             0.5,
+		ActivityFactor:    1.0,
+	}
+}
+
+// LoadTrendingConfig reads TRENDING_LOCALITY_SCALE_KM,
+// TRENDING_RECENCY_SCALE_HOURS, TRENDING_DECAY and TRENDING_ACTIVITY_FACTOR,
+// keeping the built-in default for any value that is missing or out of bounds.
+func LoadTrendingConfig() TrendingConfig {
+	config := DefaultTrendingConfig()
+	envValues := map[string]*float64{
+		"TRENDING_LOCALITY_SCALE_KM":   &config.LocalityScaleKm,
+		"TRENDING_RECENCY_SCALE_HOURS": &config.RecencyScaleHours,
+		"TRENDING_DECAY":               &config.Decay,
+		"TRENDING_ACTIVITY_FACTOR":     &config.ActivityFactor,
+	}
+	for key, target := range envValues {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			logrus.Errorf("invalid %v: %v", key, value)
+			continue
+		}
+		previous := *target
+		*target = parsed
+		if err = config.validate(); err != nil {
+			logrus.Errorf("ignoring %v: %v", key, err)
+			*target = previous
+		}
+	}
+	return config
+}
+
+func (c TrendingConfig) validate() error {
+	if c.LocalityScaleKm < MIN_LOCALITY_SCALE_KM || c.LocalityScaleKm > MAX_LOCALITY_SCALE_KM {
+		return fmt.Errorf("locality_scale must be between %v and %v km", MIN_LOCALITY_SCALE_KM, MAX_LOCALITY_SCALE_KM)
+	}
+	if c.RecencyScaleHours < MIN_RECENCY_SCALE_HOURS || c.RecencyScaleHours > MAX_RECENCY_SCALE_HOURS {
+		return fmt.Errorf("recency_scale must be between %v and %v hours", MIN_RECENCY_SCALE_HOURS, MAX_RECENCY_SCALE_HOURS)
+	}
+	if c.Decay <= 0 || c.Decay >= 1 {
+		return errors.New("decay must be between 0 and 1")
+	}
+	if c.ActivityFactor < 0 || c.ActivityFactor > MAX_ACTIVITY_FACTOR {
+		return fmt.Errorf("activity_factor must be between 0 and %v", MAX_ACTIVITY_FACTOR)
+	}
+	return nil
+}
+
+// trendingConfig applies the overrides of the request to the deployment defaults.
+func (n *NewsService) trendingConfig(request vm.FetchNewsRequest) (config TrendingConfig, err error) {
+	config = n.trending
+	if request.LocalityScale != nil {
+		config.LocalityScaleKm = *request.LocalityScale
+	}
+	if request.RecencyScale != nil {
+		config.RecencyScaleHours = *request.RecencyScale
+	}
+	if request.Decay != nil {
+		config.Decay = *request.Decay
+	}
+	if request.ActivityFactor != nil {
+		config.ActivityFactor = *request.ActivityFactor
+	}
+	err = config.validate()
+	return
+}
+
+// trendingQuery ranks the articles matching filters by activity and
+// freshness, boosting those close to location when one is given.
+func trendingQuery(config TrendingConfig, location *vm.LatLon, filters []interface{}) map[string]interface{} {
+	functions := []interface{}{
+		map[string]interface{}{
+			"field_value_factor": map[string]interface{}{
+				"field":   "recent_activity_score",
+				"factor":  config.ActivityFactor,
+				"missing": 0,
+			},
+		},
+		map[string]interface{}{
+			"gauss": map[string]interface{}{
+				"last_event_time": map[string]interface{}{
+					"origin": time.Now().Format(time.RFC3339),
+					"scale":  fmt.Sprintf("%dm", int64(config.RecencyScaleHours*60)),
+					"decay":  config.Decay,
+				},
+			},
+		},
+	}
+	if location != nil {
+		functions = append(functions, map[string]interface{}{
+			"gauss": map[string]interface{}{
+				"location": map[string]interface{}{
+					"origin": map[string]float64{
+						"lat": location.Lat,
+						"lon": location.Lon,
+					},
+					"scale":  fmt.Sprintf("%vkm", config.LocalityScaleKm),
+					"offset": "0km",
+					"decay":  config.Decay,
+				},
+			},
+		})
+	}
+
+	functionScore := map[string]interface{}{
+		"boost_mode": "sum",
+		"score_mode": "sum",
+		"functions":  functions,
+	}
+	if len(filters) > 0 {
+		functionScore["query"] = map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		}
+	}
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"function_score": functionScore,
+		},
+	}
+}
+
+// GetGlobalTrendingNews returns trending articles everywhere. lat/long or
+// location only boost nearby articles.
+func (n *NewsService) GetGlobalTrendingNews(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	return n.getTrendingNews(ctx, request, nil)
+}
+
+func (n *NewsService) GetTrendingNewsByCategory(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if strings.Trim(request.Category, " ") == "" {
+		logrus.WithContext(ctx.Ctx).Error("invalid category")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid category"))
+		return
+	}
+	return n.getTrendingNews(ctx, request, []interface{}{n.categoryTreeFilter(request.Category)})
+}
+
+func (n *NewsService) GetTrendingNewsBySource(ctx *utils.Context, request vm.FetchNewsRequest) (response vm.NewsResponse, werr utils.WrapperError) {
+	if strings.Trim(request.Source, " ") == "" {
+		logrus.WithContext(ctx.Ctx).Error("invalid source")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid source"))
+		return
+	}
+	return n.getTrendingNews(ctx, request, []interface{}{sourceFilter(request.Source)})
+}
+
+func (n *NewsService) getTrendingNews(ctx *utils.Context, request vm.FetchNewsRequest, filters []interface{}) (response vm.NewsResponse, werr utils.WrapperError) {
+	if werr = n.resolveLocation(ctx, &request); werr != nil {
+		return
+	}
+	config, err := n.trendingConfig(request)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+
+	var location *vm.LatLon
+	if hasLocation(request) {
+		location = &vm.LatLon{Lat: *request.Lat, Lon: *request.Long}
+	}
+	query := trendingQuery(config, location, filters)
+
+	elasticResponse, werr := n.searchNews(ctx, query, request)
+	if werr != nil {
+		return
+	}
+
+	err = n.mapResponse(ctx, elasticResponse, request, &response)
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, err)
+		return
+	}
+
+	return
+}
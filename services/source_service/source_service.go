@@ -0,0 +1,210 @@
+package source_service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"news_service/models"
+	"news_service/models/vm"
+	"news_service/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	DEFAULT_CREDIBILITY = 1.0
+	MAX_CREDIBILITY     = 5.0
+)
+
+// registry is an in-memory copy of the sources table.
+type registry struct {
+	byID     map[uint64]models.Source
+	byName   map[string]uint64
+	byDomain map[string]uint64
+}
+
+type SourceService struct {
+	db       *gorm.DB
+	registry *utils.TTLCache[*registry]
+}
+
+func NewSourceService(db *gorm.DB) *SourceService {
+	s := &SourceService{db: db}
+	s.registry = utils.NewTTLCache("sources", utils.CACHE_TTL, s.load, emptyRegistry)
+	return s
+}
+
+// Resolve finds the source of an article by its name or, failing that, by
+// the domain of its URL.
+func (s *SourceService) Resolve(name string, articleUrl string) (source models.Source, ok bool) {
+	r := s.registry.Get()
+	if id, found := r.byName[utils.NormalizeName(name)]; found {
+		return r.byID[id], true
+	}
+	if domain := Domain(articleUrl); domain != "" {
+		if id, found := r.byDomain[domain]; found {
+			return r.byID[id], true
+		}
+	}
+	return
+}
+
+// FindOrCreate returns the source of an article, registering a new enabled
+// source with default credibility when it is not known yet.
+func (s *SourceService) FindOrCreate(name string, articleUrl string, language string) (source models.Source, err error) {
+	if source, ok := s.Resolve(name, articleUrl); ok {
+		return source, nil
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return source, errors.New("missing source name")
+	}
+
+	source = models.Source{
+		Name:        name,
+		Domain:      Domain(articleUrl),
+		Language:    language,
+		Credibility: DEFAULT_CREDIBILITY,
+		Enabled:     true,
+	}
+	if err = s.db.Where(models.Source{Name: name}).FirstOrCreate(&source).Error; err != nil {
+		return
+	}
+	s.registry.Invalidate()
+	return
+}
+
+// IsDisabled reports whether the article with the given source ID or name
+// comes from a disabled source.
+func (s *SourceService) IsDisabled(sourceID uint64, name string) bool {
+	r := s.registry.Get()
+	if source, ok := r.byID[sourceID]; ok {
+		return !source.Enabled
+	}
+	if id, ok := r.byName[utils.NormalizeName(name)]; ok {
+		return !r.byID[id].Enabled
+	}
+	return false
+}
+
+// Disabled returns the IDs and names of every disabled source. Names are
+// needed for articles indexed before they were linked to a source ID.
+func (s *SourceService) Disabled() (ids []uint64, names []string) {
+	for _, source := range s.registry.Get().byID {
+		if !source.Enabled {
+			ids = append(ids, source.ID)
+			names = append(names, source.Name)
+		}
+	}
+	return
+}
+
+// CredibilityGroups returns the IDs of the enabled sources whose credibility
+// differs from the default, grouped by credibility.
+func (s *SourceService) CredibilityGroups() map[float64][]uint64 {
+	groups := make(map[float64][]uint64)
+	for _, source := range s.registry.Get().byID {
+		if source.Enabled && source.Credibility != DEFAULT_CREDIBILITY {
+			groups[source.Credibility] = append(groups[source.Credibility], source.ID)
+		}
+	}
+	return groups
+}
+
+func (s *SourceService) GetSources(ctx *utils.Context, request vm.SourcesRequest) (response vm.SourcesResponse, werr utils.WrapperError) {
+	response.Sources = make([]models.Source, 0)
+	if err := s.db.WithContext(ctx.Ctx).Order("name").Find(&response.Sources).Error; err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	return
+}
+
+// UpdateSource changes the metadata of a source. Disabling a source hides its
+// articles from every API within utils.CACHE_TTL on other instances and right
+// away on this one.
+func (s *SourceService) UpdateSource(ctx *utils.Context, request vm.UpdateSourceRequest) (response models.Source, werr utils.WrapperError) {
+	if request.ID == 0 {
+		logrus.WithContext(ctx.Ctx).Error("invalid id")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+	updates := map[string]interface{}{}
+	if request.Enabled != nil {
+		updates["enabled"] = *request.Enabled
+	}
+	if request.Credibility != nil {
+		if *request.Credibility <= 0 || *request.Credibility > MAX_CREDIBILITY {
+			logrus.WithContext(ctx.Ctx).Error("invalid credibility")
+			werr = utils.NewWrapperError(http.StatusBadRequest,
+				fmt.Errorf("credibility must be above 0 and at most %v", MAX_CREDIBILITY))
+			return
+		}
+		updates["credibility"] = *request.Credibility
+	}
+	if request.Country != nil {
+		updates["country"] = strings.ToUpper(*request.Country)
+	}
+	if request.Language != nil {
+		updates["language"] = strings.ToLower(*request.Language)
+	}
+	if request.Domain != nil {
+		updates["domain"] = strings.ToLower(*request.Domain)
+	}
+
+	err := s.db.WithContext(ctx.Ctx).First(&response, request.ID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		werr = utils.NewWrapperError(http.StatusNotFound, errors.New("source not found"))
+		return
+	}
+	if err == nil && len(updates) > 0 {
+		if err = s.db.WithContext(ctx.Ctx).Model(&response).Updates(updates).Error; err == nil {
+			err = s.db.WithContext(ctx.Ctx).First(&response, request.ID).Error
+		}
+	}
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	s.registry.Invalidate()
+	return
+}
+
+func (s *SourceService) load() (r *registry, err error) {
+	sources := make([]models.Source, 0)
+	if err = s.db.Find(&sources).Error; err != nil {
+		return
+	}
+	r = emptyRegistry()
+	for _, source := range sources {
+		r.byID[source.ID] = source
+		r.byName[utils.NormalizeName(source.Name)] = source.ID
+		if source.Domain != "" {
+			r.byDomain[strings.ToLower(source.Domain)] = source.ID
+		}
+	}
+	return
+}
+
+func emptyRegistry() *registry {
+	return &registry{
+		byID:     make(map[uint64]models.Source),
+		byName:   make(map[string]uint64),
+		byDomain: make(map[string]uint64),
+	}
+}
+
+// Domain returns the host of articleUrl without a leading "www.".
+func Domain(articleUrl string) string {
+	parsed, err := url.Parse(strings.Trim(articleUrl, " "))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
@@ -0,0 +1,277 @@
+package synonym_service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"news_service/models/vm"
+	"news_service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SYNONYMS_SET is the Elasticsearch synonyms set used by the
+	// news_search analyzer of the news index.
+	SYNONYMS_SET = "news-synonyms"
+	// STOPWORDS_SET holds the stop words of the news_search analyzer. Stop
+	// filters cannot be reloaded, so each stop word is a synonym rule that
+	// rewrites it to STOPWORD_TOKEN, which a fixed stop filter then drops.
+	STOPWORDS_SET  = "news-stopwords"
+	STOPWORD_TOKEN = "__stopword__"
+	// SEARCH_ANALYZER is applied to title and description at search time.
+	SEARCH_ANALYZER = "news_search"
+)
+
+// SynonymService manages the synonyms search expands queries with and the
+// stop words it ignores. Both live in Elasticsearch and can be seeded from or
+// reset to a file.
+type SynonymService struct {
+	elastic       *utils.Elastic
+	file          string
+	stopwordsFile string
+}
+
+func NewSynonymService(elastic *utils.Elastic, file string, stopwordsFile string) *SynonymService {
+	return &SynonymService{
+		elastic:       elastic,
+		file:          file,
+		stopwordsFile: stopwordsFile,
+	}
+}
+
+// LoadSynonymsFile reads one Solr format rule per line. Blank lines and lines
+// starting with # are skipped.
+func LoadSynonymsFile(path string) (rules []vm.SynonymRule, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules = make([]vm.SynonymRule, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.Trim(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules = append(rules, vm.SynonymRule{Synonyms: line})
+	}
+	return rules, scanner.Err()
+}
+
+// LoadStopwordsFile reads one stop word per line. Blank lines and lines
+// starting with # are skipped.
+func LoadStopwordsFile(path string) (stopwords []string, err error) {
+	rules, err := LoadSynonymsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stopwords = make([]string, 0, len(rules))
+	for _, rule := range rules {
+		stopwords = append(stopwords, rule.Synonyms)
+	}
+	return stopwords, nil
+}
+
+// EnsureSynonyms creates the synonyms and stop words sets from their files
+// when they do not exist yet. The news index cannot be created before the
+// sets it refers to, and an existing set is left alone so changes made
+// through the API survive restarts.
+func (s *SynonymService) EnsureSynonyms(ctx *utils.Context) (err error) {
+	err = s.ensureSet(ctx, SYNONYMS_SET, func() ([]vm.SynonymRule, error) {
+		if s.file == "" {
+			return nil, nil
+		}
+		return LoadSynonymsFile(s.file)
+	})
+	if err != nil {
+		return
+	}
+	return s.ensureSet(ctx, STOPWORDS_SET, func() ([]vm.SynonymRule, error) {
+		if s.stopwordsFile == "" {
+			return nil, nil
+		}
+		stopwords, err := LoadStopwordsFile(s.stopwordsFile)
+		if err != nil {
+			return nil, err
+		}
+		rules, err := stopwordRules(stopwords)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", s.stopwordsFile, err)
+		}
+		return rules, nil
+	})
+}
+
+func (s *SynonymService) ensureSet(ctx *utils.Context, setID string, load func() ([]vm.SynonymRule, error)) (err error) {
+	_, err = s.elastic.GetSynonyms(ctx, setID)
+	if !errors.Is(err, utils.ErrSynonymsNotFound) {
+		return
+	}
+
+	rules, err := load()
+	if err != nil {
+		logrus.Errorf("Failed to load %v, starting with an empty set: %s", setID, err)
+	}
+	if err != nil || rules == nil {
+		rules = make([]vm.SynonymRule, 0)
+	}
+	return s.elastic.PutSynonyms(ctx, setID, rules)
+}
+
+func (s *SynonymService) GetSynonyms(ctx *utils.Context, request vm.SynonymsRequest) (response vm.SynonymsResponse, werr utils.WrapperError) {
+	response, err := s.elastic.GetSynonyms(ctx, SYNONYMS_SET)
+	if errors.Is(err, utils.ErrSynonymsNotFound) {
+		werr = utils.NewWrapperError(http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	return
+}
+
+// UpdateSynonyms replaces every rule of the set with the ones in the request.
+func (s *SynonymService) UpdateSynonyms(ctx *utils.Context, request vm.SynonymsRequest) (response vm.SynonymsResponse, werr utils.WrapperError) {
+	if len(request.Rules) > utils.MAX_SYNONYM_RULES {
+		logrus.WithContext(ctx.Ctx).Error("too many synonym rules")
+		werr = utils.NewWrapperError(http.StatusBadRequest,
+			fmt.Errorf("at most %v synonym rules are allowed", utils.MAX_SYNONYM_RULES))
+		return
+	}
+	for _, rule := range request.Rules {
+		if strings.Trim(rule.Synonyms, " ") == "" || strings.ContainsAny(rule.Synonyms, "\r\n") {
+			logrus.WithContext(ctx.Ctx).Error("invalid synonym rule")
+			werr = utils.NewWrapperError(http.StatusBadRequest, fmt.Errorf("invalid synonym rule %q", rule.Synonyms))
+			return
+		}
+	}
+	return s.apply(ctx, request.Rules)
+}
+
+// ReloadSynonymsFile resets the set to the rules of the synonyms file.
+func (s *SynonymService) ReloadSynonymsFile(ctx *utils.Context, request vm.SynonymsRequest) (response vm.SynonymsResponse, werr utils.WrapperError) {
+	if s.file == "" {
+		logrus.WithContext(ctx.Ctx).Error("no synonyms file configured")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("no synonyms file configured"))
+		return
+	}
+	rules, err := LoadSynonymsFile(s.file)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("failed to read synonyms file"))
+		return
+	}
+	return s.apply(ctx, rules)
+}
+
+func (s *SynonymService) GetStopwords(ctx *utils.Context, request vm.StopwordsRequest) (response vm.StopwordsResponse, werr utils.WrapperError) {
+	set, err := s.elastic.GetSynonyms(ctx, STOPWORDS_SET)
+	if errors.Is(err, utils.ErrSynonymsNotFound) {
+		werr = utils.NewWrapperError(http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+		return
+	}
+	response.Count = set.Count
+	response.Stopwords = make([]string, 0, len(set.Rules))
+	for _, rule := range set.Rules {
+		stopword, _, _ := strings.Cut(rule.Synonyms, "=>")
+		response.Stopwords = append(response.Stopwords, strings.Trim(stopword, " "))
+	}
+	return
+}
+
+// UpdateStopwords replaces every stop word with the ones in the request.
+func (s *SynonymService) UpdateStopwords(ctx *utils.Context, request vm.StopwordsRequest) (response vm.StopwordsResponse, werr utils.WrapperError) {
+	rules, err := stopwordRules(request.Stopwords)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusBadRequest, err)
+		return
+	}
+	return s.applyStopwords(ctx, rules)
+}
+
+// ReloadStopwordsFile resets the stop words to the ones of the stop words file.
+func (s *SynonymService) ReloadStopwordsFile(ctx *utils.Context, request vm.StopwordsRequest) (response vm.StopwordsResponse, werr utils.WrapperError) {
+	if s.stopwordsFile == "" {
+		logrus.WithContext(ctx.Ctx).Error("no stop words file configured")
+		werr = utils.NewWrapperError(http.StatusBadRequest, errors.New("no stop words file configured"))
+		return
+	}
+	stopwords, err := LoadStopwordsFile(s.stopwordsFile)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, errors.New("failed to read stop words file"))
+		return
+	}
+	rules, err := stopwordRules(stopwords)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		werr = utils.NewWrapperError(http.StatusInternalServerError, fmt.Errorf("invalid stop words file: %v", err))
+		return
+	}
+	return s.applyStopwords(ctx, rules)
+}
+
+func (s *SynonymService) applyStopwords(ctx *utils.Context, rules []vm.SynonymRule) (response vm.StopwordsResponse, werr utils.WrapperError) {
+	if werr = s.store(ctx, STOPWORDS_SET, rules); werr != nil {
+		return
+	}
+	return s.GetStopwords(ctx, vm.StopwordsRequest{})
+}
+
+// stopwordRules turns stop words into the rules of STOPWORDS_SET. A stop word
+// has to be a single token, as the analyzer matches it token by token.
+func stopwordRules(stopwords []string) (rules []vm.SynonymRule, err error) {
+	if len(stopwords) > utils.MAX_SYNONYM_RULES {
+		return nil, fmt.Errorf("at most %v stop words are allowed", utils.MAX_SYNONYM_RULES)
+	}
+	rules = make([]vm.SynonymRule, 0, len(stopwords))
+	seen := make(map[string]bool)
+	for _, stopword := range stopwords {
+		stopword = strings.ToLower(strings.Trim(stopword, " "))
+		if stopword == "" || strings.ContainsAny(stopword, " \t\r\n,=>#") {
+			return nil, fmt.Errorf("invalid stop word %q", stopword)
+		}
+		if seen[stopword] {
+			continue
+		}
+		seen[stopword] = true
+		rules = append(rules, vm.SynonymRule{Synonyms: stopword + " => " + STOPWORD_TOKEN})
+	}
+	return rules, nil
+}
+
+func (s *SynonymService) apply(ctx *utils.Context, rules []vm.SynonymRule) (response vm.SynonymsResponse, werr utils.WrapperError) {
+	if werr = s.store(ctx, SYNONYMS_SET, rules); werr != nil {
+		return
+	}
+	return s.GetSynonyms(ctx, vm.SynonymsRequest{})
+}
+
+// store replaces the rules of setID and reloads the search analyzer, so
+// searches use them right away without closing or reindexing the news index.
+func (s *SynonymService) store(ctx *utils.Context, setID string, rules []vm.SynonymRule) (werr utils.WrapperError) {
+	err := s.elastic.PutSynonyms(ctx, setID, rules)
+	if errors.Is(err, utils.ErrInvalidSynonyms) {
+		return utils.NewWrapperError(http.StatusBadRequest, err)
+	}
+	if err != nil {
+		return utils.NewWrapperError(http.StatusInternalServerError, errors.New("something went wrong"))
+	}
+	if err = s.elastic.ReloadSearchAnalyzers(ctx, utils.NEWS_INDEX); err != nil {
+		return utils.NewWrapperError(http.StatusInternalServerError, errors.New("rules saved but search analyzer reload failed"))
+	}
+	return nil
+}
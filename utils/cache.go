@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CACHE_TTL is how long a table cached with TTLCache is used before it is
+// read from MySQL again, so edits to the table show up without a restart.
+const CACHE_TTL = 5 * time.Minute
+
+// TTLCache holds a value built from the database and rebuilds it once ttl has
+// passed or Invalidate is called. When load fails the previous value is kept,
+// or the value returned by empty if nothing was loaded yet.
+type TTLCache[T any] struct {
+	name     string
+	ttl      time.Duration
+	load     func() (T, error)
+	empty    func() T
+	mutex    sync.RWMutex
+	value    T
+	loaded   bool
+	loadedAt time.Time
+}
+
+func NewTTLCache[T any](name string, ttl time.Duration, load func() (T, error), empty func() T) *TTLCache[T] {
+	return &TTLCache[T]{
+		name:  name,
+		ttl:   ttl,
+		load:  load,
+		empty: empty,
+	}
+}
+
+func (c *TTLCache[T]) Get() T {
+	c.mutex.RLock()
+	value, fresh := c.value, c.loaded && time.Since(c.loadedAt) < c.ttl
+	c.mutex.RUnlock()
+	if fresh {
+		return value
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.loaded && time.Since(c.loadedAt) < c.ttl {
+		return c.value
+	}
+	loaded, err := c.load()
+	c.loadedAt = time.Now()
+	if err != nil {
+		logrus.Errorf("Failed to load %s: %s", c.name, err)
+		if !c.loaded {
+			c.value, c.loaded = c.empty(), true
+		}
+		return c.value
+	}
+	c.value, c.loaded = loaded, true
+	return c.value
+}
+
+// Invalidate makes the next Get reload the value.
+func (c *TTLCache[T]) Invalidate() {
+	c.mutex.Lock()
+	c.loadedAt = time.Time{}
+	c.mutex.Unlock()
+}
+
+// NormalizeName lowercases name and collapses runs of whitespace, so names can
+// be looked up regardless of case and spacing.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
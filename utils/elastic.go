@@ -2,7 +2,10 @@ package utils
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"news_service/models/vm"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -10,9 +13,18 @@ import (
 )
 
 const (
-	NEWS_INDEX = "news"
+	NEWS_INDEX     = "news"
+	PIT_KEEP_ALIVE = "5m"
 )
 
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the decoded form of the opaque next_cursor handed to clients.
+type cursor struct {
+	PitID       string        `json:"pit_id"`
+	SearchAfter []interface{} `json:"search_after"`
+}
+
 type Elastic struct {
 	esClient *elasticsearch.Client
 }
@@ -25,6 +37,10 @@ func NewElastic(esClient *elasticsearch.Client) *Elastic {
 
 func (e *Elastic) FetchFromElastic(ctx *Context, query map[string]interface{},
 	indexName string, paginationRequest vm.PaginationRequest) (response map[string]interface{}, err error) {
+	if paginationRequest.IsCursorMode() {
+		return e.fetchWithCursor(ctx, query, indexName, paginationRequest)
+	}
+
 	var buf bytes.Buffer
 	if err = json.NewEncoder(&buf).Encode(query); err != nil {
 		logrus.Fatalf("Error encoding query: %s", err)
@@ -52,3 +68,147 @@ func (e *Elastic) FetchFromElastic(ctx *Context, query map[string]interface{},
 	}
 	return
 }
+
+// fetchWithCursor pages through a point-in-time snapshot of the index using
+// search_after, so results stay consistent while documents are being updated
+// and are not limited by the from/size result window.
+func (e *Elastic) fetchWithCursor(ctx *Context, query map[string]interface{},
+	indexName string, paginationRequest vm.PaginationRequest) (response map[string]interface{}, err error) {
+	c := cursor{}
+	if paginationRequest.Cursor != "" {
+		if c, err = decodeCursor(paginationRequest.Cursor); err != nil {
+			logrus.WithContext(ctx.Ctx).Error(err)
+			return
+		}
+	}
+	if c.PitID == "" {
+		if c.PitID, err = e.openPointInTime(ctx, indexName); err != nil {
+			return
+		}
+	}
+
+	query["pit"] = map[string]interface{}{
+		"id":         c.PitID,
+		"keep_alive": PIT_KEEP_ALIVE,
+	}
+	if len(c.SearchAfter) > 0 {
+		query["search_after"] = c.SearchAfter
+	}
+
+	var buf bytes.Buffer
+	if err = json.NewEncoder(&buf).Encode(query); err != nil {
+		logrus.Fatalf("Error encoding query: %s", err)
+		return
+	}
+
+	searchRes, err := e.esClient.Search(
+		e.esClient.Search.WithContext(ctx.Ctx),
+		e.esClient.Search.WithBody(&buf),
+		e.esClient.Search.WithTrackTotalHits(true),
+		e.esClient.Search.WithSize(int(paginationRequest.GetLimit())),
+	)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	defer searchRes.Body.Close()
+	if searchRes.IsError() && paginationRequest.Cursor != "" &&
+		(searchRes.StatusCode == http.StatusNotFound || searchRes.StatusCode == http.StatusBadRequest) {
+		// The snapshot has expired or the cursor does not match the query.
+		logrus.WithContext(ctx.Ctx).Error(searchRes.String())
+		e.closePointInTime(ctx, c.PitID)
+		return nil, ErrInvalidCursor
+	}
+
+	response = make(map[string]interface{})
+	if err = json.NewDecoder(searchRes.Body).Decode(&response); err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	// Close the snapshot as soon as there is no next page rather than leaving
+	// it open on the cluster until PIT_KEEP_ALIVE runs out.
+	if NextCursor(response, paginationRequest.GetLimit()) == "" {
+		pitID, _ := response["pit_id"].(string)
+		if pitID == "" {
+			pitID = c.PitID
+		}
+		e.closePointInTime(ctx, pitID)
+	}
+	return
+}
+
+func (e *Elastic) openPointInTime(ctx *Context, indexName string) (pitID string, err error) {
+	res, err := e.esClient.OpenPointInTime(
+		[]string{indexName},
+		PIT_KEEP_ALIVE,
+		e.esClient.OpenPointInTime.WithContext(ctx.Ctx),
+	)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		err = errors.New(res.String())
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+
+	var pit struct {
+		ID string `json:"id"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	return pit.ID, nil
+}
+
+func (e *Elastic) closePointInTime(ctx *Context, pitID string) {
+	body, _ := json.Marshal(map[string]interface{}{"id": pitID})
+	res, err := e.esClient.ClosePointInTime(
+		e.esClient.ClosePointInTime.WithContext(ctx.Ctx),
+		e.esClient.ClosePointInTime.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		logrus.WithContext(ctx.Ctx).Error(res.String())
+	}
+}
+
+// NextCursor builds the cursor for the page following response. It is empty
+// once the last page has been reached.
+func NextCursor(response map[string]interface{}, limit int64) string {
+	pitID, _ := response["pit_id"].(string)
+	hits, _ := response["hits"].(map[string]interface{})
+	hitList, _ := hits["hits"].([]interface{})
+	if pitID == "" || len(hitList) == 0 || int64(len(hitList)) < limit {
+		return ""
+	}
+	lastHit, _ := hitList[len(hitList)-1].(map[string]interface{})
+	searchAfter, _ := lastHit["sort"].([]interface{})
+	if len(searchAfter) == 0 {
+		return ""
+	}
+	return encodeCursor(cursor{PitID: pitID, SearchAfter: searchAfter})
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (c cursor, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(data, &c); err != nil || c.PitID == "" {
+		return c, ErrInvalidCursor
+	}
+	return
+}
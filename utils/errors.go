@@ -46,8 +46,8 @@ func Recovery() {
 		}
 
 		stackString := string(debug.Stack())
-		fmt.Printf(stackString)
-		err := fmt.Errorf("Recovered from following error:", r)
+		fmt.Print(stackString)
+		err := fmt.Errorf("Recovered from following error: %v", r)
 		logrus.WithError(err).Logln(logrus.ErrorLevel)
 	}
 }
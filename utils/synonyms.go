@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"news_service/models/vm"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MAX_SYNONYM_RULES is the largest page the synonyms API returns.
+const MAX_SYNONYM_RULES = 10000
+
+var (
+	ErrInvalidSynonyms  = errors.New("invalid synonyms")
+	ErrSynonymsNotFound = errors.New("synonyms set not found")
+)
+
+// GetSynonyms returns the rules of the synonyms set setID.
+func (e *Elastic) GetSynonyms(ctx *Context, setID string) (response vm.SynonymsResponse, err error) {
+	res, err := e.esClient.SynonymsGetSynonym(
+		setID,
+		e.esClient.SynonymsGetSynonym.WithContext(ctx.Ctx),
+		e.esClient.SynonymsGetSynonym.WithSize(MAX_SYNONYM_RULES),
+	)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return response, ErrSynonymsNotFound
+	}
+	if res.IsError() {
+		err = errors.New(res.String())
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+
+	var set struct {
+		Count       int64            `json:"count"`
+		SynonymsSet []vm.SynonymRule `json:"synonyms_set"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&set); err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	return vm.SynonymsResponse{Count: set.Count, Rules: set.SynonymsSet}, nil
+}
+
+// PutSynonyms creates or replaces the synonyms set setID. Rules Elasticsearch
+// cannot parse are reported as ErrInvalidSynonyms.
+func (e *Elastic) PutSynonyms(ctx *Context, setID string, rules []vm.SynonymRule) (err error) {
+	body, err := json.Marshal(map[string]interface{}{"synonyms_set": rules})
+	if err != nil {
+		return
+	}
+	res, err := e.esClient.SynonymsPutSynonym(
+		setID,
+		bytes.NewReader(body),
+		e.esClient.SynonymsPutSynonym.WithContext(ctx.Ctx),
+	)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusBadRequest {
+		err = fmt.Errorf("%w: %v", ErrInvalidSynonyms, res.String())
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	if res.IsError() {
+		err = errors.New(res.String())
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	return
+}
+
+// ReloadSearchAnalyzers makes the search analyzers of indexName pick up the
+// current synonyms without closing the index.
+func (e *Elastic) ReloadSearchAnalyzers(ctx *Context, indexName string) (err error) {
+	res, err := e.esClient.Indices.ReloadSearchAnalyzers(
+		[]string{indexName},
+		e.esClient.Indices.ReloadSearchAnalyzers.WithContext(ctx.Ctx),
+	)
+	if err != nil {
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		err = errors.New(res.String())
+		logrus.WithContext(ctx.Ctx).Error(err)
+		return
+	}
+	return
+}